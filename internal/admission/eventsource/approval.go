@@ -0,0 +1,66 @@
+package eventsource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kanopy-platform/argoslower/pkg/hooks"
+	ingresscommon "github.com/kanopy-platform/argoslower/pkg/ingress"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// DefaultApprovalAnnotationKey approves the managed ingress of an EventSource. Approvers set any value,
+// the admission webhook replaces it with the ApprovalHash of the approved endpoints.
+const DefaultApprovalAnnotationKey string = "v1alpha1.argoslower.kanopy-platform/ingress-approved"
+
+// DefaultApprovalStatusAnnotationKey reports whether the managed ingress of an EventSource in a
+// namespace requiring approval is approved or awaiting approval.
+const DefaultApprovalStatusAnnotationKey string = "v1alpha1.argoslower.kanopy-platform/ingress-approval"
+
+const (
+	ApprovalStatusAwaiting string = "awaiting-approval"
+	ApprovalStatusApproved string = "approved"
+)
+
+// ApprovalHash returns a hash of the webhook endpoints an EventSource exposes and their known sources.
+// Changing the type, name, port, endpoint or source of a webhook changes the hash.
+func ApprovalHash(es *esv1alpha1.EventSource, sources map[string]string) string {
+	if es == nil {
+		return ""
+	}
+
+	lines := []string{fmt.Sprintf("%s/%s", es.Namespace, es.Name)}
+	for _, hook := range hooks.List(es) {
+		lines = append(lines, fmt.Sprintf("%s %s %s %s %s", hook.Type, hook.Name, hook.Context.Port, ingresscommon.NormalizeEndpoint(hook.Context.Endpoint), sources[hook.Name]))
+	}
+	sort.Strings(lines[1:])
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Approved reports whether the current webhook endpoints of an EventSource are approved.
+func Approved(es *esv1alpha1.EventSource, sources map[string]string) bool {
+	if es == nil {
+		return false
+	}
+
+	value, ok := es.Annotations[DefaultApprovalAnnotationKey]
+	return ok && value == ApprovalHash(es, sources)
+}
+
+// isApprover reports whether the requesting user is a member of one of the approver groups.
+func isApprover(user authenticationv1.UserInfo, groups []string) bool {
+	for _, group := range user.Groups {
+		if slices.Contains(groups, group) {
+			return true
+		}
+	}
+	return false
+}
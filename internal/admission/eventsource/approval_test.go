@@ -0,0 +1,53 @@
+package eventsource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+func TestApprovalHash(t *testing.T) {
+	t.Parallel()
+
+	newEventSource := func(endpoint, port string) *esv1alpha1.EventSource {
+		return &esv1alpha1.EventSource{
+			ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns"},
+			Spec: esv1alpha1.EventSourceSpec{
+				Webhook: map[string]esv1alpha1.WebhookEventSource{
+					"a": {WebhookContext: esv1alpha1.WebhookContext{Endpoint: endpoint, Port: port, AuthSecret: &corev1.SecretKeySelector{}}},
+					"b": {WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/b", Port: "12000", AuthSecret: &corev1.SecretKeySelector{}}},
+				},
+			},
+		}
+	}
+
+	sources := map[string]string{"a": "github", "b": "github"}
+	hash := ApprovalHash(newEventSource("/a", "12000"), sources)
+
+	assert.Equal(t, hash, ApprovalHash(newEventSource("/a", "12000"), sources))
+	assert.Equal(t, hash, ApprovalHash(newEventSource("a", "12000"), sources), "endpoints are normalized")
+	assert.NotEqual(t, hash, ApprovalHash(newEventSource("/c", "12000"), sources))
+	assert.NotEqual(t, hash, ApprovalHash(newEventSource("/a", "13000"), sources))
+	assert.NotEqual(t, hash, ApprovalHash(newEventSource("/a", "12000"), map[string]string{"a": "any", "b": "github"}))
+	assert.Empty(t, ApprovalHash(nil, sources))
+
+	es := newEventSource("/a", "12000")
+	assert.False(t, Approved(es, sources))
+	es.Annotations = map[string]string{DefaultApprovalAnnotationKey: hash}
+	assert.True(t, Approved(es, sources))
+	es.Spec.Webhook["a"] = esv1alpha1.WebhookEventSource{WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/c", Port: "12000"}}
+	assert.False(t, Approved(es, sources))
+}
+
+func TestIsApprover(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isApprover(authenticationv1.UserInfo{Groups: []string{"dev", "approvers"}}, []string{"approvers"}))
+	assert.False(t, isApprover(authenticationv1.UserInfo{Groups: []string{"dev"}}, []string{"approvers"}))
+	assert.False(t, isApprover(authenticationv1.UserInfo{Username: "approvers"}, []string{"approvers"}))
+}
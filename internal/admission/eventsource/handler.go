@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -13,24 +15,53 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+	"github.com/kanopy-platform/argoslower/pkg/hooks"
+	ingresscommon "github.com/kanopy-platform/argoslower/pkg/ingress"
+	"github.com/kanopy-platform/argoslower/pkg/namespace"
 
 	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
 const DefaultAnnotationKey string = "v1alpha1.argoslower.kanopy-platform/known-source"
 
+// istioRevisionLabel selects the istio revision injecting the sidecar of a pod.
+const istioRevisionLabel string = "istio.io/rev"
+
 type Handler struct {
-	annotationKey string
-	meshChecker   MeshChecker
-	decoder       admission.Decoder
-	knownSources  map[string]bool
+	annotationKey        string
+	mappingAnnotationKey string
+	statusAnnotationKey  string
+	meshChecker          MeshChecker
+	decoder              admission.Decoder
+	knownSources         map[string]bool
+	sourceAuthorizer     SourceAuthorizer
+	defaultSources       []string
+	restrictedSources    map[string]bool
+	strict               bool
+	strictExempter       StrictExempter
+	inferredSources      map[hooks.Type]string
+	generateSecrets      bool
+	secretValidation     SecretValidationMode
+	secretClient         corev1client.SecretsGetter
+	minSecretEntropy     float64
+	baseURL              string
+	approvalGroups       []string
+	approvalRequirer     ApprovalRequirer
 }
 
 func NewHandler(mc MeshChecker, knownSources map[string]bool) *Handler {
 	return &Handler{
-		annotationKey: DefaultAnnotationKey,
-		meshChecker:   mc,
-		knownSources:  knownSources,
+		annotationKey:        DefaultAnnotationKey,
+		mappingAnnotationKey: DefaultMappingAnnotationKey,
+		statusAnnotationKey:  DefaultStatusAnnotationKey,
+		meshChecker:          mc,
+		knownSources:         knownSources,
+		defaultSources:       []string{AllSources},
+		restrictedSources:    map[string]bool{},
+		secretValidation:     SecretValidationOff,
+		minSecretEntropy:     DefaultMinSecretEntropy,
 	}
 }
 
@@ -40,6 +71,73 @@ func (h *Handler) SetAnnotationKey(key string) {
 	}
 }
 
+func (h *Handler) SetMappingAnnotationKey(key string) {
+	if key != "" {
+		h.mappingAnnotationKey = key
+	}
+}
+
+// SetSourceAuthorizer configures the lookup of known sources allowed per namespace.
+func (h *Handler) SetSourceAuthorizer(sa SourceAuthorizer) {
+	h.sourceAuthorizer = sa
+}
+
+// SetDefaultSources sets the known sources allowed in namespaces without their own allowlist.
+func (h *Handler) SetDefaultSources(sources []string) {
+	h.defaultSources = sources
+}
+
+// SetRestrictedSources sets the known sources that are only allowed when explicitly listed and
+// never through the AllSources wildcard.
+func (h *Handler) SetRestrictedSources(sources map[string]bool) {
+	if sources == nil {
+		sources = map[string]bool{}
+	}
+	h.restrictedSources = sources
+}
+
+// SetStrict enables denying webhook EventSources without a known source annotation and
+// EventSource services reachable outside of the cluster. Namespaces exempted by se are not
+// enforced.
+func (h *Handler) SetStrict(strict bool, se StrictExempter) {
+	h.strict = strict
+	h.strictExempter = se
+}
+
+// SetInferredSources configures the known source defaulted for EventSources without a known source
+// annotation whose webhooks are all of one EventSource type.
+func (h *Handler) SetInferredSources(sources map[hooks.Type]string) {
+	h.inferredSources = sources
+}
+
+// SetGenerateSecrets enables wiring generated secrets into webhooks missing an authSecret and github
+// webhooks missing a webhookSecret instead of denying the EventSource.
+func (h *Handler) SetGenerateSecrets(generate bool) {
+	h.generateSecrets = generate
+}
+
+// SetSecretValidation configures whether invalid webhook secrets read through the client are
+// returned as warnings or deny the EventSource.
+func (h *Handler) SetSecretValidation(mode SecretValidationMode, client corev1client.SecretsGetter, minEntropy float64) {
+	h.secretValidation = mode
+	h.secretClient = client
+	h.minSecretEntropy = minEntropy
+}
+
+// SetBaseURL configures the public base url of managed ingress used for populating and validating
+// github webhook urls. Urls are not managed without a base url.
+func (h *Handler) SetBaseURL(url string) {
+	h.baseURL = url
+}
+
+// SetApproval restricts setting the approval annotation to members of the approver groups and
+// reports the approval status of EventSources in namespaces requiring approval. Approval is
+// disabled without groups.
+func (h *Handler) SetApproval(groups []string, ar ApprovalRequirer) {
+	h.approvalGroups = groups
+	h.approvalRequirer = ar
+}
+
 func (h *Handler) SetupWithManager(m manager.Manager) {
 	m.GetWebhookServer().Register("/mutate/eventsource", &webhook.Admission{Handler: h})
 }
@@ -63,31 +161,120 @@ func (h *Handler) Handle(ctx context.Context, req admission.Request) admission.R
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	sourceValue, ok := out.Annotations[h.annotationKey]
-	if !ok {
+	// status and finalizer updates of the controller are not subject to the admission checks, this
+	// includes releasing the finalizers of deleted EventSources
+	controllerUpdate, err := h.controllerUpdate(req, out)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if controllerUpdate {
+		return admission.Allowed("Only controller owned metadata changed")
+	}
+
+	original := out.DeepCopy()
+	warnings := h.inferSource(out)
+	resp := h.admit(ctx, req, out, warnings)
+
+	// inferred annotations are only kept when the EventSource is admitted with them, EventSources
+	// admitted without a known source before are not denied by the checks of managed ingress
+	if len(warnings) > 0 && !resp.Allowed {
+		reason := ""
+		if resp.Result != nil {
+			reason = resp.Result.Message
+		}
+		log.V(1).Info(fmt.Sprintf("Inferred known source of %s/%s not admitted: %s", out.Namespace, out.Name, reason))
+		return h.admit(ctx, req, original, []string{fmt.Sprintf("known source was not inferred: %s", reason)})
+	}
+
+	return resp
+}
+
+// admit validates and mutates an EventSource, warnings are returned with the patch response.
+func (h *Handler) admit(ctx context.Context, req admission.Request, out *esv1alpha1.EventSource, warnings []string) admission.Response {
+	log := log.FromContext(ctx)
+
+	if h.strict {
+		violations, err := h.strictViolations(req, out)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+
+		if len(violations) > 0 {
+			return admission.Denied(strings.Join(violations, " "))
+		}
+	}
+
+	if len(h.approvalGroups) > 0 {
+		approvalWarnings, violation, err := h.approve(req, out)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+
+		if violation != "" {
+			return admission.Denied(violation)
+		}
+		warnings = append(warnings, approvalWarnings...)
+	}
+
+	if !HasKnownSource(out, h.annotationKey, h.mappingAnnotationKey) {
 		log.V(1).Info("Annotation not found, ignoring eventsource")
-		return admission.Allowed("No modifications needed")
+		return admission.Allowed("No modifications needed").WithWarnings(warnings...)
+	}
+
+	if violations := h.sourceViolations(out); len(violations) > 0 {
+		return admission.Denied(strings.Join(violations, " "))
+	}
+
+	violations, err := h.namespaceViolations(out)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
-	if _, ok := h.knownSources[sourceValue]; !ok {
-		return admission.Denied(fmt.Sprintf("Unknown webhook source '%s'. Only known webhook sources are allowed.", sourceValue))
+	if len(violations) > 0 {
+		return admission.Denied(strings.Join(violations, " "))
 	}
 
-	onMesh, err := h.meshChecker.OnMesh(out.Namespace)
+	mesh, err := h.meshChecker.MeshMode(out.Namespace)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
-	if !onMesh {
+	if mesh.Mode == namespace.DataplaneNone {
 		return admission.Denied(fmt.Sprintf("Namespace %s is not opted into the mesh. Please contact your cluster administrator and try again", out.Namespace))
 	}
 
+	if h.generateSecrets {
+		if keys := SetGeneratedSecrets(out); len(keys) > 0 {
+			warnings = append(warnings, fmt.Sprintf("keys %s of secret %s are generated for webhooks without secrets", strings.Join(keys, ", "), GeneratedSecretName(out)))
+		}
+	}
+
+	sources := HookSources(out, h.annotationKey, h.mappingAnnotationKey)
+	urlViolations := SetGithubURLs(out, h.baseURL, sources)
+	if len(urlViolations) > 0 {
+		return admission.Denied(strings.Join(urlViolations, " "))
+	}
+
 	err = ValidateEventSource(out)
 	if err != nil {
 		return admission.Denied(err.Error())
 	}
 
-	out.Spec.Template = setIstioLabel(out.Spec.Template)
+	err = ValidateNestedEndpoints(out, sources)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	if h.secretValidation != SecretValidationOff && h.secretClient != nil {
+		if errs := ValidateSecrets(ctx, out, h.secretClient, h.minSecretEntropy); len(errs) > 0 {
+			if h.secretValidation == SecretValidationDeny {
+				return admission.Denied(fmt.Sprintf("Invalid webhook secrets: %s", strings.Join(errorStrings(errs), "; ")))
+			}
+			warnings = append(warnings, errorStrings(errs)...)
+		}
+	}
+
+	out.Spec.Template = setIstioLabels(out.Spec.Template, mesh)
 
 	bytes, err := json.Marshal(out)
 	if err != nil {
@@ -95,10 +282,222 @@ func (h *Handler) Handle(ctx context.Context, req admission.Request) admission.R
 		return admission.Errored(http.StatusInternalServerError, err)
 
 	}
-	return admission.PatchResponseFromRaw(req.Object.Raw, bytes)
+	return admission.PatchResponseFromRaw(req.Object.Raw, bytes).WithWarnings(warnings...)
+}
+
+// inferSource defaults the known source annotation from the configured source of the EventSource
+// type and records the type it was inferred from. Sources are only inferred once across instances,
+// EventSources carrying the inferred annotation are skipped. A warning describing the change is
+// returned.
+func (h *Handler) inferSource(es *esv1alpha1.EventSource) []string {
+	if HasKnownSource(es, h.annotationKey, h.mappingAnnotationKey) {
+		return nil
+	}
+
+	// the inferred annotation is shared by instances, EventSources another instance inferred a
+	// known source for are left to it
+	if _, ok := es.Annotations[DefaultInferredAnnotationKey]; ok {
+		return nil
+	}
+
+	t, source, ok := InferSource(es, h.inferredSources)
+	if !ok {
+		return nil
+	}
+
+	if _, ok := h.knownSources[source]; !ok {
+		return nil
+	}
+
+	if es.Annotations == nil {
+		es.Annotations = map[string]string{}
+	}
+	es.Annotations[h.annotationKey] = source
+	es.Annotations[DefaultInferredAnnotationKey] = string(t)
+
+	return []string{fmt.Sprintf("annotation %s=%s was inferred from the %s EventSource type", h.annotationKey, source, t)}
+}
+
+// approve ensures only approvers change the approval annotation and records the approved endpoints
+// in it. Approvals of endpoints that changed since are reset. EventSources in namespaces requiring
+// approval are annotated with their approval status. Annotations are checked regardless of a known
+// source so approvals cannot be staged before opting into managed ingress.
+func (h *Handler) approve(req admission.Request, es *esv1alpha1.EventSource) ([]string, string, error) {
+	previous := ""
+	if len(req.OldObject.Raw) > 0 {
+		old := &esv1alpha1.EventSource{}
+		if err := h.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return nil, "", err
+		}
+		previous = old.Annotations[DefaultApprovalAnnotationKey]
+	}
+
+	warnings := []string{}
+	hash := ApprovalHash(es, HookSources(es, h.annotationKey, h.mappingAnnotationKey))
+
+	value, ok := es.Annotations[DefaultApprovalAnnotationKey]
+	switch {
+	case ok && value != previous:
+		if !isApprover(req.UserInfo, h.approvalGroups) {
+			return nil, fmt.Sprintf("Annotation %s may only be set by members of %s.", DefaultApprovalAnnotationKey, strings.Join(h.approvalGroups, ", ")), nil
+		}
+		es.Annotations[DefaultApprovalAnnotationKey] = hash
+	case ok && value != hash:
+		delete(es.Annotations, DefaultApprovalAnnotationKey)
+		warnings = append(warnings, fmt.Sprintf("annotation %s was removed, the webhook endpoints changed since they were approved", DefaultApprovalAnnotationKey))
+	}
+
+	if h.approvalRequirer == nil || !HasKnownSource(es, h.annotationKey, h.mappingAnnotationKey) {
+		return warnings, "", nil
+	}
+
+	required, err := h.approvalRequirer.ApprovalRequired(es.Namespace)
+	if err != nil || !required {
+		return warnings, "", err
+	}
+
+	status := ApprovalStatusApproved
+	if es.Annotations[DefaultApprovalAnnotationKey] != hash {
+		status = ApprovalStatusAwaiting
+		warnings = append(warnings, fmt.Sprintf("managed ingress is awaiting approval of the webhook endpoints by a member of %s", strings.Join(h.approvalGroups, ", ")))
+	}
+
+	if es.Annotations == nil {
+		es.Annotations = map[string]string{}
+	}
+	es.Annotations[DefaultApprovalStatusAnnotationKey] = status
+
+	return warnings, "", nil
+}
+
+// sourceViolations ensures the known source annotation and every source of the mapping annotation
+// are known webhook sources and the mapping only refers to webhooks of the EventSource.
+func (h *Handler) sourceViolations(es *esv1alpha1.EventSource) []string {
+	violations := []string{}
+	if sourceValue, ok := es.Annotations[h.annotationKey]; ok {
+		if _, ok := h.knownSources[sourceValue]; !ok {
+			violations = append(violations, fmt.Sprintf("Unknown webhook source '%s'. Only known webhook sources are allowed.", sourceValue))
+		}
+	}
+
+	names := map[string]bool{}
+	for _, hook := range hooks.List(es) {
+		names[hook.Name] = true
+	}
+
+	mapping := SourceMapping(es, h.mappingAnnotationKey)
+	for _, hook := range sortedKeys(mapping) {
+		source := mapping[hook]
+		if _, ok := h.knownSources[source]; !ok {
+			violations = append(violations, fmt.Sprintf("Unknown webhook source '%s' for webhook %s. Only known webhook sources are allowed.", source, hook))
+			continue
+		}
+		if !names[hook] {
+			violations = append(violations, fmt.Sprintf("Annotation %s maps unknown webhook %s.", h.mappingAnnotationKey, hook))
+		}
+	}
+
+	return violations
 }
 
-func setIstioLabel(in *esv1alpha1.Template) *esv1alpha1.Template {
+// strictViolations ensures webhook EventSources use managed ingress and the EventSource service is
+// only reachable within the cluster. The argo-events Service spec only renders ClusterIP services,
+// the service type and externalIPs are checked on the raw object for specs carrying them anyway.
+func (h *Handler) strictViolations(req admission.Request, es *esv1alpha1.EventSource) ([]string, error) {
+	if h.strictExempter != nil {
+		exempt, err := h.strictExempter.StrictExempt(es.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if exempt {
+			return nil, nil
+		}
+	}
+
+	violations := []string{}
+	if hooks.Exposed(es) && !HasKnownSource(es, h.annotationKey, h.mappingAnnotationKey) {
+		violations = append(violations, fmt.Sprintf("Webhook EventSources require the %s annotation for managed ingress.", h.annotationKey))
+	}
+
+	raw := struct {
+		Spec struct {
+			Service *corev1.ServiceSpec `json:"service,omitempty"`
+		} `json:"spec"`
+	}{}
+
+	if err := json.Unmarshal(req.Object.Raw, &raw); err != nil {
+		return nil, err
+	}
+
+	svc := raw.Spec.Service
+	if svc == nil {
+		return violations, nil
+	}
+
+	if svc.Type == corev1.ServiceTypeLoadBalancer || svc.Type == corev1.ServiceTypeNodePort {
+		violations = append(violations, fmt.Sprintf("EventSource services of type %s are not allowed.", svc.Type))
+	}
+
+	if len(svc.ExternalIPs) > 0 {
+		violations = append(violations, "EventSource services with externalIPs are not allowed.")
+	}
+
+	for _, port := range svc.Ports {
+		if port.NodePort != 0 {
+			violations = append(violations, fmt.Sprintf("EventSource service port %d must not set a nodePort.", port.Port))
+		}
+	}
+
+	return violations, nil
+}
+
+// namespaceViolations ensures every source claimed by the EventSource is allowed in its namespace.
+func (h *Handler) namespaceViolations(es *esv1alpha1.EventSource) ([]string, error) {
+	allowed := h.defaultSources
+	if h.sourceAuthorizer != nil {
+		sources, ok, err := h.sourceAuthorizer.AllowedSources(es.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			allowed = sources
+		}
+	}
+
+	claimed := map[string]bool{}
+	if source, ok := es.Annotations[h.annotationKey]; ok {
+		claimed[source] = true
+	}
+	for _, source := range SourceMapping(es, h.mappingAnnotationKey) {
+		claimed[source] = true
+	}
+
+	violations := []string{}
+	for _, source := range sortedKeys(claimed) {
+		if !SourceAllowed(source, allowed, h.restrictedSources[source]) {
+			violations = append(violations, fmt.Sprintf("Webhook source '%s' is not allowed in namespace %s. Please contact your cluster administrator and try again.", source, es.Namespace))
+		}
+	}
+
+	return violations, nil
+}
+
+func sortedKeys[V any](in map[string]V) []string {
+	out := make([]string, 0, len(in))
+	for k := range in {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// setIstioLabels labels the EventSource pods for sidecar injection of the namespace revision.
+// Ambient mode pods are captured by the node dataplane and are left unchanged.
+func setIstioLabels(in *esv1alpha1.Template, mesh namespace.MeshInfo) *esv1alpha1.Template {
+	if mesh.Mode == namespace.DataplaneAmbient {
+		return in
+	}
+
 	out := in.DeepCopy()
 	if out == nil {
 		out = &esv1alpha1.Template{}
@@ -114,17 +513,37 @@ func setIstioLabel(in *esv1alpha1.Template) *esv1alpha1.Template {
 	}
 
 	out.Metadata.Labels["sidecar.istio.io/inject"] = "true"
+	if mesh.Revision != "" {
+		out.Metadata.Labels[istioRevisionLabel] = mesh.Revision
+	}
 
 	return out
 }
 
+// MeshChecker detects how a namespace is enrolled in the mesh.
 type MeshChecker interface {
-	OnMesh(namespace string) (bool, error)
+	MeshMode(ns string) (namespace.MeshInfo, error)
+}
+
+// StrictExempter reports whether a namespace is exempt from strict mode enforcement.
+type StrictExempter interface {
+	StrictExempt(ns string) (bool, error)
+}
+
+// SourceAuthorizer returns the known sources allowed in a namespace. The returned bool is false
+// when the namespace does not configure its own allowlist.
+type SourceAuthorizer interface {
+	AllowedSources(namespace string) ([]string, bool, error)
+}
+
+// ApprovalRequirer reports whether exposing webhooks of EventSources in a namespace requires approval.
+type ApprovalRequirer interface {
+	ApprovalRequired(ns string) (bool, error)
 }
 
 func ValidateEventSource(es *esv1alpha1.EventSource) error {
 
-	if len(es.Spec.Webhook) == 0 && len(es.Spec.Github) == 0 {
+	if len(es.Spec.Webhook) == 0 && len(es.Spec.Github) == 0 && len(es.Spec.Slack) == 0 && len(es.Spec.Stripe) == 0 && len(es.Spec.SNS) == 0 {
 		return perrs.NewUnretryableError(fmt.Errorf("EventSource %s/%s has no supported webhook configuration", es.Namespace, es.Name))
 	}
 
@@ -159,6 +578,98 @@ func ValidateEventSource(es *esv1alpha1.EventSource) error {
 
 	}
 
+	if es.Spec.Slack != nil {
+		var err error
+
+		for hook, spec := range es.Spec.Slack {
+			e := validateSlackEventSource(&spec)
+			if e != nil {
+				err = perrs.NewUnretryableError(errors.Join(err, fmt.Errorf("slack webhook %s misconfigured: %w", hook, e)))
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if es.Spec.Stripe != nil {
+		var err error
+
+		for hook, spec := range es.Spec.Stripe {
+			e := validateStripeEventSource(&spec)
+			if e != nil {
+				err = perrs.NewUnretryableError(errors.Join(err, fmt.Errorf("stripe webhook %s misconfigured: %w", hook, e)))
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if es.Spec.SNS != nil {
+		var err error
+
+		for hook, spec := range es.Spec.SNS {
+			e := validateSNSEventSource(&spec)
+			if e != nil {
+				err = perrs.NewUnretryableError(errors.Join(err, fmt.Errorf("sns webhook %s misconfigured: %w", hook, e)))
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return validateEndpoints(es)
+}
+
+// validateEndpoints ensures no two webhooks of an EventSource share an endpoint. Webhooks are routed
+// below the /namespace/name prefix of the EventSource on a shared host regardless of their port.
+func validateEndpoints(es *esv1alpha1.EventSource) error {
+	var err error
+
+	paths := map[string]hooks.Hook{}
+	for _, hook := range hooks.List(es) {
+		path := ingresscommon.NormalizeEndpoint(hook.Context.Endpoint)
+		if other, ok := paths[path]; ok {
+			err = errors.Join(err, fmt.Errorf("%s webhook %s endpoint %s collides with %s webhook %s", hook.Type, hook.Name, path, other.Type, other.Name))
+			continue
+		}
+		paths[path] = hook
+	}
+
+	if err != nil {
+		return perrs.NewUnretryableError(err)
+	}
+
+	return nil
+}
+
+// ValidateNestedEndpoints ensures no webhook endpoint is a prefix of the endpoint of a webhook with a
+// different known source. The ingress of an endpoint also matches its sub paths, requests to the nested
+// endpoint would have to originate from the CIDRs of both sources.
+func ValidateNestedEndpoints(es *esv1alpha1.EventSource, sources map[string]string) error {
+	var err error
+
+	list := hooks.List(es)
+	for _, parent := range list {
+		for _, hook := range list {
+			if sources[parent.Name] == sources[hook.Name] || !ingresscommon.NestedEndpoint(parent.Context.Endpoint, hook.Context.Endpoint) {
+				continue
+			}
+			err = errors.Join(err, fmt.Errorf("%s webhook %s endpoint %s with known source %s is a prefix of %s webhook %s endpoint %s with known source %s",
+				parent.Type, parent.Name, ingresscommon.NormalizeEndpoint(parent.Context.Endpoint), sources[parent.Name],
+				hook.Type, hook.Name, ingresscommon.NormalizeEndpoint(hook.Context.Endpoint), sources[hook.Name]))
+		}
+	}
+
+	if err != nil {
+		return perrs.NewUnretryableError(err)
+	}
+
 	return nil
 }
 
@@ -181,3 +692,34 @@ func validateGithubEventSource(spec *esv1alpha1.GithubEventSource) error {
 
 	return nil
 }
+
+func validateSlackEventSource(spec *esv1alpha1.SlackEventSource) error {
+	// Slack signs requests with the app signing secret, verification is implemented by argo-events
+	// https://github.com/argoproj/argo-events/blob/v1.9.6/pkg/eventsources/sources/slack/start.go#L328
+	if spec.SigningSecret == nil {
+		return perrs.NewUnretryableError(fmt.Errorf("slack webhook EventSources require request signing validation for ingress. Ensure a signingSecret secret selector is provided"))
+	}
+
+	return nil
+}
+
+func validateStripeEventSource(spec *esv1alpha1.StripeEventSource) error {
+	// argo-events only uses the Stripe api key to register the webhook endpoint, payload signatures
+	// are not verified and the endpoint would accept unsigned requests.
+	// https://github.com/argoproj/argo-events/blob/v1.9.6/pkg/eventsources/sources/stripe/start.go#L149
+	if spec.Webhook != nil {
+		return perrs.NewUnretryableError(fmt.Errorf("stripe webhook EventSources are not supported for ingress, argo-events does not verify Stripe webhook signatures"))
+	}
+
+	return nil
+}
+
+func validateSNSEventSource(spec *esv1alpha1.SNSEventSource) error {
+	// SNS signs notification messages with an AWS certificate, verification is implemented by argo-events
+	// when validateSignature is enabled
+	if !spec.ValidateSignature {
+		return perrs.NewUnretryableError(fmt.Errorf("sns webhook EventSources require message signature validation for ingress. Ensure validateSignature is enabled"))
+	}
+
+	return nil
+}
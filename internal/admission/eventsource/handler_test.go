@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/kanopy-platform/argoslower/internal/admission/eventsource"
 	estest "github.com/kanopy-platform/argoslower/internal/admission/eventsource/testing"
+	"github.com/kanopy-platform/argoslower/pkg/hooks"
+	"github.com/kanopy-platform/argoslower/pkg/namespace"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -126,20 +129,762 @@ func TestEventSourceHandler(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			name: "Mapped sources",
+			es: esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						eventsource.DefaultMappingAnnotationKey: "ghs=github",
+					},
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					Github: map[string]esv1alpha1.GithubEventSource{
+						"ghs": esv1alpha1.GithubEventSource{
+							WebhookSecret: &corev1.SecretKeySelector{},
+							Webhook:       &esv1alpha1.WebhookContext{},
+						},
+					},
+				},
+			},
+			key: eventsource.DefaultMappingAnnotationKey,
+		},
+		{
+			name: "Mapped unknown source",
+			es: esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						eventsource.DefaultMappingAnnotationKey: "ghs=unknown-source",
+					},
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					Github: map[string]esv1alpha1.GithubEventSource{
+						"ghs": esv1alpha1.GithubEventSource{
+							WebhookSecret: &corev1.SecretKeySelector{},
+							Webhook:       &esv1alpha1.WebhookContext{},
+						},
+					},
+				},
+			},
+			key: eventsource.DefaultMappingAnnotationKey,
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		if test.key == "" {
+			test.key = eventsource.DefaultAnnotationKey
+		}
+
+		if test.nsOnMesh != nil {
+			fmc.Mesh = false
+		}
+
+		fmc.Err = test.nsErr
+
+		esb, err := json.Marshal(test.es)
+		require.NoError(t, err)
+
+		ar := admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{
+				Raw: esb,
+			},
+		}
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+		fmc.Mesh = true
+		fmc.Err = nil
+
+		if test.nsErr != nil {
+			assert.False(t, resp.Allowed, test.name)
+			assert.Equal(t, test.nsErr.Error(), resp.Result.Message, test.name)
+			continue
+		}
+
+		_, ok := test.es.Annotations[test.key]
+		if !ok {
+			assert.True(t, resp.Allowed, test.name)
+			assert.Equal(t, 0, len(resp.Patches), test.name)
+			continue
+		}
+
+		if test.err {
+			assert.False(t, resp.Allowed, test.name)
+			assert.Contains(t, resp.Result.Reason, "Forbidden", test.name)
+			assert.Contains(t, resp.Result.Message, "Unknown webhook source", test.name)
+			continue
+		}
+
+		if test.nsOnMesh != nil {
+			assert.False(t, resp.Allowed, test.name)
+			continue
+		}
+
+		assert.True(t, resp.Allowed, test.name)
+		assert.Equal(t, 1, len(resp.Patches), test.name)
+	}
+}
+
+func TestEventSourceHandlerMeshModes(t *testing.T) {
+	t.Parallel()
+
+	fmc := &estest.FakeMeshChecker{Mesh: true}
+	handler := eventsource.NewHandler(fmc, map[string]bool{"github": true})
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	es := esv1alpha1.EventSource{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{
+				eventsource.DefaultAnnotationKey: "github",
+			},
+		},
+		Spec: esv1alpha1.EventSourceSpec{
+			Github: map[string]esv1alpha1.GithubEventSource{
+				"ghs": esv1alpha1.GithubEventSource{
+					WebhookSecret: &corev1.SecretKeySelector{},
+				},
+			},
+		},
+	}
+
+	esb, err := json.Marshal(es)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		info     namespace.MeshInfo
+		expected map[string]string
+	}{
+		{
+			name: "sidecar",
+			info: namespace.MeshInfo{Mode: namespace.DataplaneSidecar},
+			expected: map[string]string{
+				"sidecar.istio.io/inject": "true",
+			},
+		},
+		{
+			name: "sidecar revision",
+			info: namespace.MeshInfo{Mode: namespace.DataplaneSidecar, Revision: "stable"},
+			expected: map[string]string{
+				"sidecar.istio.io/inject": "true",
+				"istio.io/rev":            "stable",
+			},
+		},
+		{
+			name: "ambient",
+			info: namespace.MeshInfo{Mode: namespace.DataplaneAmbient},
+		},
+	}
+
+	for _, test := range tests {
+		fmc.Info = test.info
+
+		ar := admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{
+				Raw: esb,
+			},
+		}
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+		assert.True(t, resp.Allowed, test.name)
+
+		labels := map[string]string{}
+		for _, patch := range resp.Patches {
+			if patch.Path != "/spec/template" {
+				continue
+			}
+			b, err := json.Marshal(patch.Value)
+			require.NoError(t, err)
+			template := esv1alpha1.Template{}
+			require.NoError(t, json.Unmarshal(b, &template))
+			labels = template.Metadata.Labels
+		}
+
+		if test.expected == nil {
+			assert.Empty(t, resp.Patches, test.name)
+			continue
+		}
+
+		assert.Equal(t, test.expected, labels, test.name)
+	}
+}
+
+func TestEventSourceHandlerInferredSource(t *testing.T) {
+	t.Parallel()
+
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: true}, map[string]bool{"github": true})
+	handler.SetInferredSources(map[hooks.Type]string{hooks.Github: "github", hooks.Slack: "slack"})
+	handler.SetStrict(true, nil)
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	github := map[string]esv1alpha1.GithubEventSource{
+		"ghs": esv1alpha1.GithubEventSource{
+			WebhookSecret: &corev1.SecretKeySelector{},
+			Webhook:       &esv1alpha1.WebhookContext{Endpoint: "/push", Port: "12000"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		es       esv1alpha1.EventSource
+		allowed  bool
+		inferred bool
+	}{
+		{
+			name: "github only",
+			es: esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{Github: github},
+			},
+			allowed:  true,
+			inferred: true,
+		},
+		{
+			name: "annotated",
+			es: esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{eventsource.DefaultAnnotationKey: "github"},
+				},
+				Spec: esv1alpha1.EventSourceSpec{Github: github},
+			},
+			allowed: true,
+		},
+		{
+			name: "inferred by another instance",
+			es: esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						"tenant.example.com/known-source":        "github",
+						eventsource.DefaultInferredAnnotationKey: "github",
+					},
+				},
+				Spec: esv1alpha1.EventSourceSpec{Github: github},
+			},
+		},
+		{
+			name: "mixed types",
+			es: esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Github: github,
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"hook": {WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/hook", Port: "12000"}},
+					},
+				},
+			},
+		},
+		{
+			name: "provider not configured",
+			es: esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Slack: map[string]esv1alpha1.SlackEventSource{
+						"slack": {
+							SigningSecret: &corev1.SecretKeySelector{},
+							Webhook:       &esv1alpha1.WebhookContext{Endpoint: "/slack", Port: "12000"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		esb, err := json.Marshal(test.es)
+		require.NoError(t, err)
+
+		ar := admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{
+				Raw: esb,
+			},
+		}
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+		assert.Equal(t, test.allowed, resp.Allowed, test.name)
+
+		annotations := map[string]string{}
+		for _, patch := range resp.Patches {
+			if patch.Path == "/metadata/annotations" {
+				b, err := json.Marshal(patch.Value)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(b, &annotations))
+			}
+		}
+
+		if test.inferred {
+			assert.Equal(t, map[string]string{
+				eventsource.DefaultAnnotationKey:         "github",
+				eventsource.DefaultInferredAnnotationKey: "github",
+			}, annotations, test.name)
+			assert.Len(t, resp.Warnings, 1, test.name)
+			continue
+		}
+
+		assert.Empty(t, annotations, test.name)
+		assert.Empty(t, resp.Warnings, test.name)
+	}
+}
+
+func TestEventSourceHandlerInferredSourceNotAdmitted(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+
+	tests := []struct {
+		name     string
+		mesh     bool
+		secret   *corev1.SecretKeySelector
+		inferred bool
+	}{
+		{
+			name:     "admitted",
+			mesh:     true,
+			secret:   &corev1.SecretKeySelector{},
+			inferred: true,
+		},
+		{
+			name:   "namespace off mesh",
+			secret: &corev1.SecretKeySelector{},
+		},
+		{
+			name: "missing webhook secret",
+			mesh: true,
+		},
+	}
+
+	for _, test := range tests {
+		handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: test.mesh}, map[string]bool{"github": true})
+		handler.SetInferredSources(map[hooks.Type]string{hooks.Github: "github"})
+		require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+		es := esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{Name: "bar", Namespace: "foo"},
+			Spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{
+					"ghs": esv1alpha1.GithubEventSource{
+						WebhookSecret: test.secret,
+						Webhook:       &esv1alpha1.WebhookContext{Endpoint: "/push", Port: "12000"},
+					},
+				},
+			},
+		}
+		esb, err := json.Marshal(es)
+		require.NoError(t, err)
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: esb},
+		}})
+
+		// EventSources failing the checks of managed ingress are admitted unchanged as before
+		assert.True(t, resp.Allowed, test.name)
+		require.Len(t, resp.Warnings, 1, test.name)
+		if test.inferred {
+			assert.NotEmpty(t, resp.Patches, test.name)
+			continue
+		}
+
+		assert.Empty(t, resp.Patches, test.name)
+		assert.Contains(t, resp.Warnings[0], "known source was not inferred", test.name)
+	}
+}
+
+func TestEventSourceHandlerGenerateSecrets(t *testing.T) {
+	t.Parallel()
+
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: true}, map[string]bool{"github": true})
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	es := esv1alpha1.EventSource{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "bar",
+			Namespace: "foo",
+			Annotations: map[string]string{
+				eventsource.DefaultAnnotationKey: "github",
+			},
+		},
+		Spec: esv1alpha1.EventSourceSpec{
+			Github: map[string]esv1alpha1.GithubEventSource{
+				"ghs": esv1alpha1.GithubEventSource{
+					Webhook: &esv1alpha1.WebhookContext{Endpoint: "/push", Port: "12000"},
+				},
+			},
+		},
+	}
+
+	esb, err := json.Marshal(es)
+	require.NoError(t, err)
+
+	ar := admissionv1.AdmissionRequest{
+		Object: runtime.RawExtension{
+			Raw: esb,
+		},
+	}
+
+	resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+	assert.False(t, resp.Allowed)
+
+	handler.SetGenerateSecrets(true)
+	resp = handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+	assert.True(t, resp.Allowed)
+	assert.Len(t, resp.Warnings, 1)
+
+	paths := []string{}
+	for _, patch := range resp.Patches {
+		paths = append(paths, patch.Path)
+	}
+	assert.Contains(t, paths, "/spec/github/ghs/webhookSecret")
+	assert.Contains(t, paths, "/metadata/annotations/v1alpha1.argoslower.kanopy-platform~1generated-secret")
+}
+
+func TestEventSourceHandlerSecretValidation(t *testing.T) {
+	t.Parallel()
+
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: true}, map[string]bool{"github": true})
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	client := newSecretClient(&corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "tokens", Namespace: "foo"},
+		Data: map[string][]byte{
+			"short": []byte("abc123"),
+		},
+	})
+
+	es := esv1alpha1.EventSource{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "bar",
+			Namespace: "foo",
+			Annotations: map[string]string{
+				eventsource.DefaultAnnotationKey: "github",
+			},
+		},
+		Spec: esv1alpha1.EventSourceSpec{
+			Github: map[string]esv1alpha1.GithubEventSource{
+				"ghs": esv1alpha1.GithubEventSource{
+					WebhookSecret: selector("tokens", "short"),
+				},
+			},
+		},
+	}
+
+	esb, err := json.Marshal(es)
+	require.NoError(t, err)
+
+	ar := admissionv1.AdmissionRequest{
+		Object: runtime.RawExtension{
+			Raw: esb,
+		},
+	}
+
+	tests := []struct {
+		mode     eventsource.SecretValidationMode
+		allowed  bool
+		warnings int
+	}{
+		{mode: eventsource.SecretValidationOff, allowed: true},
+		{mode: eventsource.SecretValidationWarn, allowed: true, warnings: 1},
+		{mode: eventsource.SecretValidationDeny},
+	}
+
+	for _, test := range tests {
+		handler.SetSecretValidation(test.mode, client, eventsource.DefaultMinSecretEntropy)
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+		assert.Equal(t, test.allowed, resp.Allowed, test.mode)
+		assert.Len(t, resp.Warnings, test.warnings, test.mode)
+		if !test.allowed {
+			assert.Contains(t, resp.Result.Message, "shorter than 12 characters", test.mode)
+		}
+	}
+}
+
+func TestEventSourceHandlerGithubURL(t *testing.T) {
+	t.Parallel()
+
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: true}, map[string]bool{"github": true})
+	handler.SetBaseURL("webhooks.example.com")
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	tests := []struct {
+		name    string
+		url     string
+		allowed bool
+		patched bool
+	}{
+		{name: "filled", allowed: true, patched: true},
+		{name: "matching", url: "https://webhooks.example.com/foo/bar", allowed: true},
+		{name: "mismatch", url: "https://webhooks.example.com/foo/other"},
+	}
+
+	for _, test := range tests {
+		es := esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "bar",
+				Namespace: "foo",
+				Annotations: map[string]string{
+					eventsource.DefaultAnnotationKey: "github",
+				},
+			},
+			Spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{
+					"ghs": esv1alpha1.GithubEventSource{
+						WebhookSecret: &corev1.SecretKeySelector{},
+						Webhook:       &esv1alpha1.WebhookContext{Endpoint: "/push", Port: "12000", URL: test.url},
+					},
+				},
+			},
+		}
+
+		esb, err := json.Marshal(es)
+		require.NoError(t, err)
+
+		ar := admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{
+				Raw: esb,
+			},
+		}
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+		assert.Equal(t, test.allowed, resp.Allowed, test.name)
+		if !test.allowed {
+			assert.Contains(t, resp.Result.Message, "does not match the managed ingress url", test.name)
+			continue
+		}
+
+		url := ""
+		for _, patch := range resp.Patches {
+			if patch.Path == "/spec/github/ghs/webhook/url" {
+				url, _ = patch.Value.(string)
+			}
+		}
+
+		if test.patched {
+			assert.Equal(t, "https://webhooks.example.com/foo/bar", url, test.name)
+			continue
+		}
+		assert.Empty(t, url, test.name)
+	}
+}
+
+func TestEventSourceHandlerStrict(t *testing.T) {
+	t.Parallel()
+
+	fse := &estest.FakeStrictExempter{
+		Exempt: map[string]bool{"exempt": true},
+	}
+
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: true}, map[string]bool{"github": true})
+	handler.SetStrict(true, fse)
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	const github = `"github": {"ghs": {"webhookSecret": {"key": "secret"}, "webhook": {"endpoint": "/push", "port": "12000"}}}`
+
+	tests := []struct {
+		name      string
+		raw       string
+		exemptErr error
+		allowed   bool
+		message   string
+	}{
+		{
+			name:    "no webhooks",
+			raw:     `{"metadata": {"namespace": "foo"}, "spec": {"calendar": {"c": {"interval": "1m"}}}}`,
+			allowed: true,
+		},
+		{
+			name:    "unmanaged webhook",
+			raw:     `{"metadata": {"namespace": "foo"}, "spec": {` + github + `}}`,
+			message: "annotation for managed ingress",
+		},
+		{
+			name:    "deleted unmanaged webhook",
+			raw:     `{"metadata": {"namespace": "foo", "deletionTimestamp": "2024-01-01T00:00:00Z"}, "spec": {` + github + `}}`,
+			message: "annotation for managed ingress",
+		},
+		{
+			name:    "unmanaged unsupported webhook",
+			raw:     `{"metadata": {"namespace": "foo"}, "spec": {"gitlab": {"gl": {"webhook": {"endpoint": "/push", "port": "12000"}}}}}`,
+			message: "annotation for managed ingress",
+		},
+		{
+			name:    "managed webhook",
+			raw:     `{"metadata": {"namespace": "foo", "annotations": {"` + eventsource.DefaultAnnotationKey + `": "github"}}, "spec": {` + github + `}}`,
+			allowed: true,
+		},
+		{
+			name:    "load balancer service",
+			raw:     `{"metadata": {"namespace": "foo", "annotations": {"` + eventsource.DefaultAnnotationKey + `": "github"}}, "spec": {"service": {"type": "LoadBalancer", "ports": [{"port": 12000}]}, ` + github + `}}`,
+			message: "type LoadBalancer are not allowed",
+		},
+		{
+			name:    "node port service",
+			raw:     `{"metadata": {"namespace": "foo"}, "spec": {"service": {"type": "NodePort", "ports": [{"port": 12000}]}}}`,
+			message: "type NodePort are not allowed",
+		},
+		{
+			name:    "external ips",
+			raw:     `{"metadata": {"namespace": "foo"}, "spec": {"service": {"externalIPs": ["10.0.0.1"], "ports": [{"port": 12000}]}}}`,
+			message: "externalIPs are not allowed",
+		},
+		{
+			name:    "node port",
+			raw:     `{"metadata": {"namespace": "foo"}, "spec": {"service": {"ports": [{"port": 12000, "nodePort": 30000}]}}}`,
+			message: "must not set a nodePort",
+		},
+		{
+			name:    "cluster ip service",
+			raw:     `{"metadata": {"namespace": "foo"}, "spec": {"service": {"ports": [{"port": 12000}]}}}`,
+			allowed: true,
+		},
+		{
+			name:    "exempt namespace",
+			raw:     `{"metadata": {"namespace": "exempt"}, "spec": {"service": {"type": "LoadBalancer", "ports": [{"port": 12000}]}, ` + github + `}}`,
+			allowed: true,
+		},
+		{
+			name:      "exempter error",
+			raw:       `{"metadata": {"namespace": "foo"}, "spec": {` + github + `}}`,
+			exemptErr: errors.New("test error"),
+			message:   "test error",
+		},
+	}
+
+	for _, test := range tests {
+		fse.Err = test.exemptErr
+
+		ar := admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{
+				Raw: []byte(test.raw),
+			},
+		}
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+		assert.Equal(t, test.allowed, resp.Allowed, test.name)
+		if !test.allowed {
+			assert.Contains(t, resp.Result.Message, test.message, test.name)
+		}
+	}
+	fse.Err = nil
+}
+
+func TestEventSourceHandlerAllowedSources(t *testing.T) {
+	t.Parallel()
+
+	fsa := &estest.FakeSourceAuthorizer{
+		Sources: map[string][]string{
+			"github-only": {"github"},
+			"debug":       {"*", "any"},
+			"none":        {},
+		},
+	}
+
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: true}, map[string]bool{"github": true, "jira": true, "any": true})
+	handler.SetSourceAuthorizer(fsa)
+	handler.SetRestrictedSources(map[string]bool{"any": true})
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	tests := []struct {
+		name      string
+		namespace string
+		source    string
+		mapping   string
+		defaults  []string
+		authErr   error
+		allowed   bool
+	}{
+		{
+			name:      "default wildcard allows unrestricted source",
+			namespace: "default",
+			source:    "jira",
+			allowed:   true,
+		},
+		{
+			name:      "default wildcard denies restricted source",
+			namespace: "default",
+			source:    "any",
+		},
+		{
+			name:      "namespace allowlist permits listed source",
+			namespace: "github-only",
+			source:    "github",
+			allowed:   true,
+		},
+		{
+			name:      "namespace allowlist denies unlisted source",
+			namespace: "github-only",
+			source:    "jira",
+		},
+		{
+			name:      "namespace allowlist denies unlisted mapped source",
+			namespace: "github-only",
+			source:    "github",
+			mapping:   "ghs=jira",
+		},
+		{
+			name:      "restricted source explicitly allowed",
+			namespace: "debug",
+			source:    "any",
+			allowed:   true,
+		},
+		{
+			name:      "empty namespace allowlist denies all sources",
+			namespace: "none",
+			source:    "github",
+		},
+		{
+			name:      "cluster default restricts namespaces without allowlist",
+			namespace: "default",
+			source:    "jira",
+			defaults:  []string{"github"},
+		},
+		{
+			name:      "authorizer error",
+			namespace: "default",
+			source:    "github",
+			authErr:   errors.New("test error"),
+		},
 	}
 
 	for _, test := range tests {
-		if test.key == "" {
-			test.key = eventsource.DefaultAnnotationKey
+		defaults := test.defaults
+		if defaults == nil {
+			defaults = []string{eventsource.AllSources}
 		}
+		handler.SetDefaultSources(defaults)
+		fsa.Err = test.authErr
 
-		if test.nsOnMesh != nil {
-			fmc.Mesh = false
+		annotations := map[string]string{
+			eventsource.DefaultAnnotationKey: test.source,
+		}
+		if test.mapping != "" {
+			annotations[eventsource.DefaultMappingAnnotationKey] = test.mapping
 		}
 
-		fmc.Err = test.nsErr
+		es := esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace:   test.namespace,
+				Annotations: annotations,
+			},
+			Spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{
+					"ghs": esv1alpha1.GithubEventSource{
+						WebhookSecret: &corev1.SecretKeySelector{},
+						Webhook:       &esv1alpha1.WebhookContext{},
+					},
+				},
+			},
+		}
 
-		esb, err := json.Marshal(test.es)
+		esb, err := json.Marshal(es)
 		require.NoError(t, err)
 
 		ar := admissionv1.AdmissionRequest{
@@ -149,36 +894,82 @@ func TestEventSourceHandler(t *testing.T) {
 		}
 
 		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
-		fmc.Mesh = true
-		fmc.Err = nil
+		assert.Equal(t, test.allowed, resp.Allowed, test.name)
 
-		if test.nsErr != nil {
-			assert.False(t, resp.Allowed, test.name)
-			assert.Equal(t, test.nsErr.Error(), resp.Result.Message, test.name)
+		if test.authErr != nil {
+			assert.Equal(t, test.authErr.Error(), resp.Result.Message, test.name)
 			continue
 		}
 
-		_, ok := test.es.Annotations[test.key]
-		if !ok {
-			assert.True(t, resp.Allowed, test.name)
-			assert.Equal(t, 0, len(resp.Patches), test.name)
-			continue
+		if !test.allowed {
+			assert.Contains(t, resp.Result.Message, "is not allowed in namespace", test.name)
 		}
+	}
+	fsa.Err = nil
+}
 
-		if test.err {
-			assert.False(t, resp.Allowed, test.name)
-			assert.Contains(t, resp.Result.Reason, "Forbidden", test.name)
-			assert.Contains(t, resp.Result.Message, "Unknown webhook source", test.name)
-			continue
+func TestEventSourceHandlerNestedEndpoints(t *testing.T) {
+	t.Parallel()
+
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: true}, map[string]bool{"github": true, "jira": true})
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	tests := map[string]struct {
+		mapping string
+		nested  string
+		allowed bool
+	}{
+		"nested endpoint with the same source": {
+			nested:  "/push/jira",
+			allowed: true,
+		},
+		"nested endpoint with a different source": {
+			mapping: "jira=jira",
+			nested:  "/push/jira",
+		},
+		"sibling endpoint with a different source": {
+			mapping: "jira=jira",
+			nested:  "/pushed",
+			allowed: true,
+		},
+	}
+
+	for name, test := range tests {
+		annotations := map[string]string{
+			eventsource.DefaultAnnotationKey: "github",
+		}
+		if test.mapping != "" {
+			annotations[eventsource.DefaultMappingAnnotationKey] = test.mapping
 		}
 
-		if test.nsOnMesh != nil {
-			assert.False(t, resp.Allowed, test.name)
-			continue
+		es := esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "nested",
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+			Spec: esv1alpha1.EventSourceSpec{
+				Webhook: map[string]esv1alpha1.WebhookEventSource{
+					"push": esv1alpha1.WebhookEventSource{
+						WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/push", AuthSecret: &corev1.SecretKeySelector{}},
+					},
+					"jira": esv1alpha1.WebhookEventSource{
+						WebhookContext: esv1alpha1.WebhookContext{Endpoint: test.nested, AuthSecret: &corev1.SecretKeySelector{}},
+					},
+				},
+			},
 		}
 
-		assert.True(t, resp.Allowed, test.name)
-		assert.Equal(t, 1, len(resp.Patches), test.name)
+		esb, err := json.Marshal(es)
+		require.NoError(t, err)
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Object: runtime.RawExtension{Raw: esb}}})
+		assert.Equal(t, test.allowed, resp.Allowed, name)
+		if !test.allowed {
+			assert.Contains(t, resp.Result.Message, "webhook push endpoint /push with known source github is a prefix of webhook webhook jira", name)
+		}
 	}
 }
 
@@ -296,6 +1087,151 @@ func TestValidateEventSource(t *testing.T) {
 			},
 			err: true,
 		},
+		"colliding endpoints": {
+			spec: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "collision",
+					Namespace: "testing",
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"ws": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								AuthSecret: &corev1.SecretKeySelector{},
+								Endpoint:   "push",
+								Port:       "12000",
+							},
+						},
+					},
+					Github: map[string]esv1alpha1.GithubEventSource{
+						"ghs": esv1alpha1.GithubEventSource{
+							WebhookSecret: &corev1.SecretKeySelector{},
+							Webhook: &esv1alpha1.WebhookContext{
+								Endpoint: "/push",
+								Port:     "13000",
+							},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"distinct endpoints": {
+			spec: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "valid",
+					Namespace: "testing",
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"a": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								AuthSecret: &corev1.SecretKeySelector{},
+								Endpoint:   "/push",
+								Port:       "12000",
+							},
+						},
+						"b": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								AuthSecret: &corev1.SecretKeySelector{},
+								Endpoint:   "/push/nested",
+								Port:       "12000",
+							},
+						},
+					},
+				},
+			},
+		},
+		"slack no secret": {
+			spec: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "nosecret",
+					Namespace: "testing",
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					Slack: map[string]esv1alpha1.SlackEventSource{
+						"nos": esv1alpha1.SlackEventSource{
+							Token: &corev1.SecretKeySelector{},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"slack valid": {
+			spec: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "valid",
+					Namespace: "testing",
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					Slack: map[string]esv1alpha1.SlackEventSource{
+						"ss": esv1alpha1.SlackEventSource{
+							SigningSecret: &corev1.SecretKeySelector{},
+						},
+					},
+				},
+			},
+		},
+		"stripe without webhook": {
+			spec: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "nowebhook",
+					Namespace: "testing",
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					Stripe: map[string]esv1alpha1.StripeEventSource{
+						"nw": esv1alpha1.StripeEventSource{},
+					},
+				},
+			},
+		},
+		"stripe webhook": {
+			spec: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "webhook",
+					Namespace: "testing",
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					Stripe: map[string]esv1alpha1.StripeEventSource{
+						"sk": esv1alpha1.StripeEventSource{
+							Webhook: &esv1alpha1.WebhookContext{Endpoint: "/stripe", Port: "12000"},
+							APIKey:  &corev1.SecretKeySelector{},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"sns no signature validation": {
+			spec: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "nosignature",
+					Namespace: "testing",
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					SNS: map[string]esv1alpha1.SNSEventSource{
+						"nos": esv1alpha1.SNSEventSource{},
+					},
+				},
+			},
+			err: true,
+		},
+		"sns valid": {
+			spec: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "valid",
+					Namespace: "testing",
+				},
+				Spec: esv1alpha1.EventSourceSpec{
+					SNS: map[string]esv1alpha1.SNSEventSource{
+						"sns": esv1alpha1.SNSEventSource{
+							ValidateSignature: true,
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -309,3 +1245,172 @@ func TestValidateEventSource(t *testing.T) {
 	}
 
 }
+
+func TestEventSourceHandlerApproval(t *testing.T) {
+	t.Parallel()
+
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{Mesh: true}, map[string]bool{"github": true})
+	handler.SetApproval([]string{"approvers"}, &estest.FakeApprovalRequirer{Required: map[string]bool{"regulated": true}})
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	newEventSource := func(namespace, endpoint, approval string) *esv1alpha1.EventSource {
+		es := &esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "es",
+				Namespace:   namespace,
+				Annotations: map[string]string{eventsource.DefaultAnnotationKey: "github"},
+			},
+			Spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{
+					"ghs": {
+						WebhookSecret: &corev1.SecretKeySelector{Key: "secret"},
+						Webhook:       &esv1alpha1.WebhookContext{Endpoint: endpoint, Port: "12000"},
+					},
+				},
+			},
+		}
+		if approval != "" {
+			es.Annotations[eventsource.DefaultApprovalAnnotationKey] = approval
+		}
+		return es
+	}
+
+	approved := newEventSource("regulated", "/push", "")
+	hash := eventsource.ApprovalHash(approved, map[string]string{"ghs": "github"})
+
+	tests := []struct {
+		name     string
+		old      *esv1alpha1.EventSource
+		new      *esv1alpha1.EventSource
+		groups   []string
+		allowed  bool
+		approval string
+		status   string
+		warning  string
+	}{
+		{
+			name:    "awaiting approval",
+			new:     newEventSource("regulated", "/push", ""),
+			allowed: true,
+			status:  eventsource.ApprovalStatusAwaiting,
+			warning: "awaiting approval",
+		},
+		{
+			name:     "approved by approver",
+			old:      newEventSource("regulated", "/push", ""),
+			new:      newEventSource("regulated", "/push", "true"),
+			groups:   []string{"developers", "approvers"},
+			allowed:  true,
+			approval: hash,
+			status:   eventsource.ApprovalStatusApproved,
+		},
+		{
+			name:   "approval by non approver",
+			old:    newEventSource("regulated", "/push", ""),
+			new:    newEventSource("regulated", "/push", "true"),
+			groups: []string{"developers"},
+		},
+		{
+			name:   "forged approval on create",
+			new:    newEventSource("regulated", "/push", hash),
+			groups: []string{"developers"},
+		},
+		{
+			name:    "unchanged approval",
+			old:     newEventSource("regulated", "/push", hash),
+			new:     newEventSource("regulated", "/push", hash),
+			groups:  []string{"developers"},
+			allowed: true,
+			status:  eventsource.ApprovalStatusApproved,
+		},
+		{
+			name:    "changed endpoints reset approval",
+			old:     newEventSource("regulated", "/push", hash),
+			new:     newEventSource("regulated", "/other", hash),
+			groups:  []string{"developers"},
+			allowed: true,
+			status:  eventsource.ApprovalStatusAwaiting,
+			warning: "endpoints changed",
+		},
+		{
+			name:    "approval not required",
+			new:     newEventSource("default", "/push", ""),
+			allowed: true,
+		},
+		{
+			name:   "approvals are restricted in every namespace",
+			new:    newEventSource("default", "/push", "true"),
+			groups: []string{"developers"},
+		},
+	}
+
+	for _, test := range tests {
+		raw, err := json.Marshal(test.new)
+		require.NoError(t, err, test.name)
+
+		ar := admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: raw},
+		}
+		ar.UserInfo.Groups = test.groups
+		if test.old != nil {
+			oldRaw, err := json.Marshal(test.old)
+			require.NoError(t, err, test.name)
+			ar.OldObject = runtime.RawExtension{Raw: oldRaw}
+		}
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: ar})
+		assert.Equal(t, test.allowed, resp.Allowed, test.name)
+		if !test.allowed {
+			assert.Contains(t, resp.Result.Message, "may only be set by members of approvers", test.name)
+			continue
+		}
+
+		annotations := patchedAnnotations(t, test.new, resp)
+		assert.Equal(t, test.status, annotations[eventsource.DefaultApprovalStatusAnnotationKey], test.name)
+		if test.approval != "" {
+			assert.Equal(t, test.approval, annotations[eventsource.DefaultApprovalAnnotationKey], test.name)
+		}
+		if test.status == eventsource.ApprovalStatusAwaiting {
+			assert.NotContains(t, annotations, eventsource.DefaultApprovalAnnotationKey, test.name)
+		}
+
+		if test.warning != "" {
+			require.NotEmpty(t, resp.Warnings, test.name)
+			assert.Contains(t, strings.Join(resp.Warnings, " "), test.warning, test.name)
+		}
+	}
+}
+
+// patchedAnnotations applies the annotation patches of a response to the annotations of an EventSource.
+func patchedAnnotations(t *testing.T, es *esv1alpha1.EventSource, resp admission.Response) map[string]string {
+	t.Helper()
+
+	out := map[string]string{}
+	for k, v := range es.Annotations {
+		out[k] = v
+	}
+
+	const prefix = "/metadata/annotations/"
+	for _, patch := range resp.Patches {
+		switch {
+		case patch.Path == "/metadata/annotations":
+			out = map[string]string{}
+			values, ok := patch.Value.(map[string]interface{})
+			require.True(t, ok)
+			for k, v := range values {
+				out[k], _ = v.(string)
+			}
+		case strings.HasPrefix(patch.Path, prefix):
+			key := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(patch.Path, prefix))
+			if patch.Operation == "remove" {
+				delete(out, key)
+				continue
+			}
+			out[key], _ = patch.Value.(string)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,51 @@
+package eventsource
+
+import (
+	"maps"
+	"slices"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultStatusAnnotationKey carries the JSON encoded ingress status the eventsource controller reports
+// on EventSources with managed ingress.
+const DefaultStatusAnnotationKey string = "v1alpha1.argoslower.kanopy-platform/ingress-status"
+
+// SetStatusAnnotationKey configures the ingress status annotation written by the eventsource controller.
+func (h *Handler) SetStatusAnnotationKey(key string) {
+	if key != "" {
+		h.statusAnnotationKey = key
+	}
+}
+
+// controllerUpdate reports whether an update only changes metadata owned by the eventsource controller,
+// the ingress status annotation or finalizers. The controller reports the status of EventSources the
+// admission checks would deny since, i.e. in namespaces that left the mesh, and releases the finalizers
+// of deleted EventSources.
+func (h *Handler) controllerUpdate(req admission.Request, es *esv1alpha1.EventSource) (bool, error) {
+	if len(req.OldObject.Raw) == 0 {
+		return false, nil
+	}
+
+	old := &esv1alpha1.EventSource{}
+	if err := h.decoder.DecodeRaw(req.OldObject, old); err != nil {
+		return false, err
+	}
+
+	if old.Annotations[h.statusAnnotationKey] == es.Annotations[h.statusAnnotationKey] && slices.Equal(old.Finalizers, es.Finalizers) {
+		return false, nil
+	}
+
+	return apiequality.Semantic.DeepEqual(old.Spec, es.Spec) &&
+		apiequality.Semantic.DeepEqual(old.Labels, es.Labels) &&
+		maps.Equal(h.userAnnotations(old), h.userAnnotations(es)), nil
+}
+
+// userAnnotations returns the annotations of an EventSource without the controller owned ones.
+func (h *Handler) userAnnotations(es *esv1alpha1.EventSource) map[string]string {
+	out := maps.Clone(es.Annotations)
+	delete(out, h.statusAnnotationKey)
+	return out
+}
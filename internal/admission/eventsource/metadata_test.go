@@ -0,0 +1,139 @@
+package eventsource_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	estest "github.com/kanopy-platform/argoslower/internal/admission/eventsource/testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+func TestEventSourceHandlerControllerUpdate(t *testing.T) {
+	t.Parallel()
+
+	// the namespace left the mesh, EventSources with a known source are denied
+	handler := eventsource.NewHandler(&estest.FakeMeshChecker{}, map[string]bool{"github": true})
+	scheme := runtime.NewScheme()
+	utilruntime.Must(esv1alpha1.AddToScheme(scheme))
+	require.NoError(t, handler.InjectDecoder(admission.NewDecoder(scheme)))
+
+	newEventSource := func() *esv1alpha1.EventSource {
+		return &esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "es",
+				Namespace:   "off-mesh",
+				Annotations: map[string]string{eventsource.DefaultAnnotationKey: "github"},
+				Finalizers:  []string{"argoslower.kanopy-platform/ingress"},
+			},
+			Spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{
+					"ghs": {
+						WebhookSecret: &corev1.SecretKeySelector{Key: "secret"},
+						Webhook:       &esv1alpha1.WebhookContext{Endpoint: "/push", Port: "12000"},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		deleting bool
+		mutate   func(es *esv1alpha1.EventSource)
+		allowed  bool
+	}{
+		{
+			name: "status",
+			mutate: func(es *esv1alpha1.EventSource) {
+				es.Annotations[eventsource.DefaultStatusAnnotationKey] = `{"ready":false,"reason":"NamespaceOffMesh"}`
+			},
+			allowed: true,
+		},
+		{
+			name: "finalizer released",
+			mutate: func(es *esv1alpha1.EventSource) {
+				es.Finalizers = nil
+			},
+			allowed: true,
+		},
+		{
+			name: "status and spec",
+			mutate: func(es *esv1alpha1.EventSource) {
+				es.Annotations[eventsource.DefaultStatusAnnotationKey] = `{}`
+				es.Spec.Github["ghs"].Webhook.Endpoint = "/other"
+			},
+		},
+		{
+			name: "status and annotations",
+			mutate: func(es *esv1alpha1.EventSource) {
+				es.Annotations[eventsource.DefaultStatusAnnotationKey] = `{}`
+				es.Annotations[eventsource.DefaultMappingAnnotationKey] = "ghs=github"
+			},
+		},
+		{
+			name:   "unchanged",
+			mutate: func(es *esv1alpha1.EventSource) {},
+		},
+		{
+			name:     "deleting finalizer released",
+			deleting: true,
+			mutate: func(es *esv1alpha1.EventSource) {
+				es.Finalizers = nil
+			},
+			allowed: true,
+		},
+		{
+			name:     "deleting spec",
+			deleting: true,
+			mutate: func(es *esv1alpha1.EventSource) {
+				es.Spec.Github["ghs"].Webhook.Endpoint = "/other"
+			},
+		},
+		{
+			name:     "deleting finalizer released and annotations",
+			deleting: true,
+			mutate: func(es *esv1alpha1.EventSource) {
+				es.Finalizers = nil
+				es.Annotations[eventsource.DefaultMappingAnnotationKey] = "ghs=github"
+			},
+		},
+	}
+
+	for _, test := range tests {
+		old := newEventSource()
+		updated := newEventSource()
+		if test.deleting {
+			now := v1.Now()
+			old.DeletionTimestamp = &now
+			updated.DeletionTimestamp = &now
+		}
+		test.mutate(updated)
+
+		oldRaw, err := json.Marshal(old)
+		require.NoError(t, err, test.name)
+		raw, err := json.Marshal(updated)
+		require.NoError(t, err, test.name)
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Object:    runtime.RawExtension{Raw: raw},
+			OldObject: runtime.RawExtension{Raw: oldRaw},
+		}})
+		assert.Equal(t, test.allowed, resp.Allowed, test.name)
+		if test.allowed {
+			assert.Empty(t, resp.Patches, test.name)
+		}
+	}
+}
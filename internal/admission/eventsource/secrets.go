@@ -0,0 +1,105 @@
+package eventsource
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kanopy-platform/argoslower/pkg/hooks"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultGeneratedSecretAnnotationKey names the Secret argoslower generates missing webhook tokens and
+// HMAC keys of an EventSource in. The Secret is created and owned by the eventsource controller.
+const DefaultGeneratedSecretAnnotationKey string = "v1alpha1.argoslower.kanopy-platform/generated-secret"
+
+// GeneratedSecretName returns the name of the Secret holding the generated webhook secrets of an EventSource.
+func GeneratedSecretName(es *esv1alpha1.EventSource) string {
+	return fmt.Sprintf("%s-argoslower-webhooks", es.Name)
+}
+
+// GeneratedSecretKey returns the Secret key of the generated secret of a webhook.
+func GeneratedSecretKey(t hooks.Type, name string) string {
+	return fmt.Sprintf("%s-%s", t, name)
+}
+
+// SetGeneratedSecrets wires a generated secret selector into every webhook missing an authSecret and
+// every github webhook missing a webhookSecret, and records the generated Secret name in the
+// EventSource annotations. The wired Secret keys are returned sorted.
+func SetGeneratedSecrets(es *esv1alpha1.EventSource) []string {
+	if es == nil || es.Name == "" {
+		return nil
+	}
+
+	name := GeneratedSecretName(es)
+	keys := []string{}
+
+	for hook, spec := range es.Spec.Webhook {
+		if spec.AuthSecret != nil {
+			continue
+		}
+		key := GeneratedSecretKey(hooks.Webhook, hook)
+		spec.AuthSecret = secretSelector(name, key)
+		es.Spec.Webhook[hook] = spec
+		keys = append(keys, key)
+	}
+
+	for hook, spec := range es.Spec.Github {
+		if spec.WebhookSecret != nil {
+			continue
+		}
+		key := GeneratedSecretKey(hooks.Github, hook)
+		spec.WebhookSecret = secretSelector(name, key)
+		es.Spec.Github[hook] = spec
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if es.Annotations == nil {
+		es.Annotations = map[string]string{}
+	}
+	es.Annotations[DefaultGeneratedSecretAnnotationKey] = name
+
+	sort.Strings(keys)
+	return keys
+}
+
+// GeneratedSecretKeys returns the sorted keys of the generated Secret referenced by the EventSource
+// secret selectors.
+func GeneratedSecretKeys(es *esv1alpha1.EventSource) []string {
+	if es == nil {
+		return nil
+	}
+
+	name, ok := es.Annotations[DefaultGeneratedSecretAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	keys := []string{}
+	for _, spec := range es.Spec.Webhook {
+		if spec.AuthSecret != nil && spec.AuthSecret.Name == name {
+			keys = append(keys, spec.AuthSecret.Key)
+		}
+	}
+
+	for _, spec := range es.Spec.Github {
+		if spec.WebhookSecret != nil && spec.WebhookSecret.Name == name {
+			keys = append(keys, spec.WebhookSecret.Key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func secretSelector(name, key string) *corev1.SecretKeySelector {
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		Key:                  key,
+	}
+}
@@ -0,0 +1,112 @@
+package eventsource_test
+
+import (
+	"testing"
+
+	"github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+func TestSetGeneratedSecrets(t *testing.T) {
+	t.Parallel()
+
+	existing := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "user-secret"},
+		Key:                  "token",
+	}
+
+	tests := map[string]struct {
+		es       *esv1alpha1.EventSource
+		expected []string
+	}{
+		"nil": {},
+		"no name": {
+			es: &esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{"hook": {}},
+				},
+			},
+		},
+		"secrets configured": {
+			es: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{Name: "foo"},
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{"hook": {WebhookContext: esv1alpha1.WebhookContext{AuthSecret: existing}}},
+					Github:  map[string]esv1alpha1.GithubEventSource{"gh": {WebhookSecret: existing}},
+				},
+			},
+		},
+		"missing secrets": {
+			es: &esv1alpha1.EventSource{
+				ObjectMeta: v1.ObjectMeta{Name: "foo"},
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"hook":     {},
+						"existing": {WebhookContext: esv1alpha1.WebhookContext{AuthSecret: existing}},
+					},
+					Github: map[string]esv1alpha1.GithubEventSource{"gh": {}},
+					Slack:  map[string]esv1alpha1.SlackEventSource{"slack": {}},
+				},
+			},
+			expected: []string{"github-gh", "webhook-hook"},
+		},
+	}
+
+	for name, test := range tests {
+		keys := eventsource.SetGeneratedSecrets(test.es)
+		assert.Equal(t, test.expected, keys, name)
+
+		if test.expected == nil {
+			if test.es != nil {
+				assert.NotContains(t, test.es.Annotations, eventsource.DefaultGeneratedSecretAnnotationKey, name)
+			}
+			continue
+		}
+
+		secretName := eventsource.GeneratedSecretName(test.es)
+		assert.Equal(t, "foo-argoslower-webhooks", secretName, name)
+		assert.Equal(t, secretName, test.es.Annotations[eventsource.DefaultGeneratedSecretAnnotationKey], name)
+		assert.Equal(t, existing, test.es.Spec.Webhook["existing"].AuthSecret, name)
+		assert.Equal(t, "webhook-hook", test.es.Spec.Webhook["hook"].AuthSecret.Key, name)
+		assert.Equal(t, secretName, test.es.Spec.Webhook["hook"].AuthSecret.Name, name)
+		assert.Equal(t, "github-gh", test.es.Spec.Github["gh"].WebhookSecret.Key, name)
+		assert.Nil(t, test.es.Spec.Slack["slack"].SigningSecret, name)
+		assert.Equal(t, test.expected, eventsource.GeneratedSecretKeys(test.es), name)
+	}
+}
+
+func TestGeneratedSecretKeys(t *testing.T) {
+	t.Parallel()
+
+	selector := func(name, key string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+	}
+
+	es := &esv1alpha1.EventSource{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "foo",
+			Annotations: map[string]string{
+				eventsource.DefaultGeneratedSecretAnnotationKey: "generated",
+			},
+		},
+		Spec: esv1alpha1.EventSourceSpec{
+			Webhook: map[string]esv1alpha1.WebhookEventSource{
+				"a": {WebhookContext: esv1alpha1.WebhookContext{AuthSecret: selector("generated", "webhook-a")}},
+				"b": {WebhookContext: esv1alpha1.WebhookContext{AuthSecret: selector("user", "token")}},
+			},
+			Github: map[string]esv1alpha1.GithubEventSource{
+				"gh": {WebhookSecret: selector("generated", "github-gh")},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"github-gh", "webhook-a"}, eventsource.GeneratedSecretKeys(es))
+
+	delete(es.Annotations, eventsource.DefaultGeneratedSecretAnnotationKey)
+	assert.Nil(t, eventsource.GeneratedSecretKeys(es))
+}
@@ -0,0 +1,153 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// MinSecretLength is the shortest bearer token the webhook VirtualService forwards.
+const MinSecretLength int = 12
+
+// DefaultMinSecretEntropy is the default minimum estimated entropy of a webhook secret in bits.
+const DefaultMinSecretEntropy float64 = 36
+
+// SecretValidationMode configures how the admission webhook reports webhook secret violations.
+type SecretValidationMode string
+
+const (
+	SecretValidationOff  SecretValidationMode = "off"
+	SecretValidationWarn SecretValidationMode = "warn"
+	SecretValidationDeny SecretValidationMode = "deny"
+)
+
+func ParseSecretValidationMode(in string) (SecretValidationMode, error) {
+	switch mode := SecretValidationMode(in); mode {
+	case SecretValidationOff, SecretValidationWarn, SecretValidationDeny:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid secret validation mode %q, want one of off, warn or deny", in)
+	}
+}
+
+// ValidateSecrets ensures the authSecret of every webhook and the webhookSecret of every github webhook
+// exist, contain the key, and are at least MinSecretLength characters with minEntropy bits of
+// estimated entropy. The Secret generated by argoslower is skipped while it is yet to be created or
+// controlled by the EventSource. Secrets are read with direct GETs, only the referenced Secrets in the
+// namespace of the EventSource are requested.
+func ValidateSecrets(ctx context.Context, es *esv1alpha1.EventSource, client corev1client.SecretsGetter, minEntropy float64) []error {
+	selectors := map[string]*corev1.SecretKeySelector{}
+	for hook, spec := range es.Spec.Webhook {
+		if spec.AuthSecret != nil {
+			selectors[fmt.Sprintf("webhook %s authSecret", hook)] = spec.AuthSecret
+		}
+	}
+
+	for hook, spec := range es.Spec.Github {
+		if spec.WebhookSecret != nil {
+			selectors[fmt.Sprintf("github webhook %s webhookSecret", hook)] = spec.WebhookSecret
+		}
+	}
+
+	name := GeneratedSecretName(es)
+	generated := es.Annotations[DefaultGeneratedSecretAnnotationKey] == name
+
+	errs := []error{}
+	for _, field := range sortedKeys(selectors) {
+		selector := selectors[field]
+		if generated && selector.Name == name && generatedSecret(ctx, es, client) {
+			continue
+		}
+
+		if err := validateSecret(ctx, es.Namespace, selector, client, minEntropy); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+		}
+	}
+
+	return errs
+}
+
+// generatedSecret reports whether the generated Secret of an EventSource is yet to be created or is
+// controlled by the EventSource. Secrets created under the generated name by anyone else are validated.
+func generatedSecret(ctx context.Context, es *esv1alpha1.EventSource, client corev1client.SecretsGetter) bool {
+	secret, err := client.Secrets(es.Namespace).Get(ctx, GeneratedSecretName(es), metav1.GetOptions{})
+	if k8serror.IsNotFound(err) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+
+	owner := metav1.GetControllerOf(secret)
+	return owner != nil &&
+		owner.APIVersion == esv1alpha1.SchemeGroupVersion.String() &&
+		owner.Kind == "EventSource" &&
+		owner.Name == es.Name &&
+		(es.UID == "" || owner.UID == es.UID)
+}
+
+func validateSecret(ctx context.Context, namespace string, selector *corev1.SecretKeySelector, client corev1client.SecretsGetter, minEntropy float64) error {
+	secret, err := client.Secrets(namespace).Get(ctx, selector.Name, metav1.GetOptions{})
+	if k8serror.IsNotFound(err) {
+		if selector.Optional != nil && *selector.Optional {
+			return nil
+		}
+		return fmt.Errorf("secret %s/%s not found", namespace, selector.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return fmt.Errorf("secret %s/%s has no key %s", namespace, selector.Name, selector.Key)
+	}
+
+	token := strings.TrimSpace(string(value))
+	if len(token) < MinSecretLength {
+		return fmt.Errorf("secret %s/%s key %s is shorter than %d characters", namespace, selector.Name, selector.Key, MinSecretLength)
+	}
+
+	if entropy := Entropy(token); entropy < minEntropy {
+		return fmt.Errorf("secret %s/%s key %s has an estimated entropy of %.0f bits, want at least %.0f", namespace, selector.Name, selector.Key, entropy, minEntropy)
+	}
+
+	return nil
+}
+
+// Entropy estimates the entropy of a secret in bits from the Shannon entropy of its characters.
+func Entropy(in string) float64 {
+	if in == "" {
+		return 0
+	}
+
+	counts := map[rune]float64{}
+	total := 0.0
+	for _, r := range in {
+		counts[r]++
+		total++
+	}
+
+	perChar := 0.0
+	for _, count := range counts {
+		p := count / total
+		perChar -= p * math.Log2(p)
+	}
+
+	return perChar * total
+}
+
+func errorStrings(errs []error) []string {
+	out := make([]string, 0, len(errs))
+	for _, err := range errs {
+		out = append(out, err.Error())
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package eventsource_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+func newSecretClient(secrets ...runtime.Object) corev1client.SecretsGetter {
+	return fake.NewSimpleClientset(secrets...).CoreV1()
+}
+
+func selector(name, key string) *corev1.SecretKeySelector {
+	return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	t.Parallel()
+
+	controller := true
+	client := newSecretClient(&corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "tokens", Namespace: "foo"},
+		Data: map[string][]byte{
+			"strong":   []byte("Zq3vX9kLm2Rt7WpY4sNb\n"),
+			"short":    []byte("abc123"),
+			"repeated": []byte("aaaaaaaaaaaaaaaaaaaaaaaa"),
+		},
+	}, &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "owned-argoslower-webhooks", Namespace: "foo", OwnerReferences: []v1.OwnerReference{{
+			APIVersion: esv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "EventSource",
+			Name:       "owned",
+			UID:        "uid",
+			Controller: &controller,
+		}}},
+		Data: map[string][]byte{"webhook-hook": []byte("short")},
+	}, &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "bar-argoslower-webhooks", Namespace: "foo"},
+		Data:       map[string][]byte{"webhook-hook": []byte("short")},
+	})
+
+	optional := true
+	missingOptional := selector("missing", "token")
+	missingOptional.Optional = &optional
+
+	tests := map[string]struct {
+		eventSource string
+		webhook     *corev1.SecretKeySelector
+		github      *corev1.SecretKeySelector
+		generated   string
+		expected    []string
+	}{
+		"strong": {
+			webhook: selector("tokens", "strong"),
+			github:  selector("tokens", "strong"),
+		},
+		"short": {
+			webhook:  selector("tokens", "short"),
+			expected: []string{"webhook hook authSecret: secret foo/tokens key short is shorter than 12 characters"},
+		},
+		"low entropy": {
+			github:   selector("tokens", "repeated"),
+			expected: []string{"github webhook gh webhookSecret: secret foo/tokens key repeated has an estimated entropy of 0 bits, want at least 36"},
+		},
+		"missing key": {
+			webhook:  selector("tokens", "missing"),
+			expected: []string{"webhook hook authSecret: secret foo/tokens has no key missing"},
+		},
+		"missing secret": {
+			webhook: selector("missing", "token"),
+			github:  selector("missing", "token"),
+			expected: []string{
+				"github webhook gh webhookSecret: secret foo/missing not found",
+				"webhook hook authSecret: secret foo/missing not found",
+			},
+		},
+		"missing optional secret": {
+			webhook: missingOptional,
+		},
+		"generated secret": {
+			eventSource: "new",
+			webhook:     selector("new-argoslower-webhooks", "webhook-hook"),
+			generated:   "new-argoslower-webhooks",
+		},
+		"generated secret controlled by the eventsource": {
+			eventSource: "owned",
+			webhook:     selector("owned-argoslower-webhooks", "webhook-hook"),
+			generated:   "owned-argoslower-webhooks",
+		},
+		"generated secret not controlled by the eventsource": {
+			webhook:   selector("bar-argoslower-webhooks", "webhook-hook"),
+			generated: "bar-argoslower-webhooks",
+			expected:  []string{"webhook hook authSecret: secret foo/bar-argoslower-webhooks key webhook-hook is shorter than 12 characters"},
+		},
+		"annotated secret of another name": {
+			webhook:   selector("tokens", "short"),
+			generated: "tokens",
+			expected:  []string{"webhook hook authSecret: secret foo/tokens key short is shorter than 12 characters"},
+		},
+	}
+
+	for name, test := range tests {
+		if test.eventSource == "" {
+			test.eventSource = "bar"
+		}
+		es := &esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{Name: test.eventSource, Namespace: "foo", UID: "uid"},
+		}
+		if test.generated != "" {
+			es.Annotations = map[string]string{eventsource.DefaultGeneratedSecretAnnotationKey: test.generated}
+		}
+		if test.webhook != nil {
+			es.Spec.Webhook = map[string]esv1alpha1.WebhookEventSource{
+				"hook": {WebhookContext: esv1alpha1.WebhookContext{AuthSecret: test.webhook}},
+			}
+		}
+		if test.github != nil {
+			es.Spec.Github = map[string]esv1alpha1.GithubEventSource{
+				"gh": {WebhookSecret: test.github},
+			}
+		}
+
+		errs := eventsource.ValidateSecrets(context.TODO(), es, client, eventsource.DefaultMinSecretEntropy)
+		messages := []string{}
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+
+		if test.expected == nil {
+			test.expected = []string{}
+		}
+		assert.Equal(t, test.expected, messages, name)
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0.0, eventsource.Entropy(""))
+	assert.Equal(t, 0.0, eventsource.Entropy("aaaa"))
+	assert.InDelta(t, 8.0, eventsource.Entropy("abcd"), 0.001)
+	assert.InDelta(t, 4.0, eventsource.Entropy("abab"), 0.001)
+}
+
+func TestParseSecretValidationMode(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{"off", "warn", "deny"} {
+		parsed, err := eventsource.ParseSecretValidationMode(mode)
+		assert.NoError(t, err)
+		assert.Equal(t, eventsource.SecretValidationMode(mode), parsed)
+	}
+
+	_, err := eventsource.ParseSecretValidationMode("strict")
+	assert.Error(t, err)
+}
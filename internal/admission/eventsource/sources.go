@@ -0,0 +1,125 @@
+package eventsource
+
+import (
+	"strings"
+
+	"github.com/kanopy-platform/argoslower/pkg/hooks"
+	stringutils "github.com/kanopy-platform/argoslower/pkg/stringutils"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+// DefaultMappingAnnotationKey assigns known sources to individual webhooks of an EventSource as a
+// comma separated webhookName=source list. Webhooks that are not listed use the known source
+// annotation value.
+const DefaultMappingAnnotationKey string = "v1alpha1.argoslower.kanopy-platform/known-source-mapping"
+
+// DefaultInferredAnnotationKey records the EventSource type a known source annotation was inferred from.
+const DefaultInferredAnnotationKey string = "v1alpha1.argoslower.kanopy-platform/known-source-inferred"
+
+// AllSources allows every unrestricted known source when present in a source allowlist.
+const AllSources string = "*"
+
+// SourceMapping returns the per webhook known source mapping configured by the mappingKey annotation.
+func SourceMapping(es *esv1alpha1.EventSource, mappingKey string) map[string]string {
+	out := map[string]string{}
+	if es == nil {
+		return out
+	}
+
+	value, ok := es.Annotations[mappingKey]
+	if !ok {
+		return out
+	}
+
+	for hook, source := range stringutils.StringToMap(value, ",", "=") {
+		hook = strings.TrimSpace(hook)
+		source = strings.TrimSpace(source)
+		if hook == "" || source == "" {
+			continue
+		}
+		out[hook] = source
+	}
+
+	return out
+}
+
+// HookSources returns the known source of every supported webhook of an EventSource keyed by the
+// webhook name. Webhooks present in the mapping annotation use the mapped source and all others use
+// the value of the known source annotation. Webhooks without a known source are omitted.
+func HookSources(es *esv1alpha1.EventSource, annotationKey, mappingKey string) map[string]string {
+	out := map[string]string{}
+	if es == nil {
+		return out
+	}
+
+	defaultSource := es.Annotations[annotationKey]
+	mapping := SourceMapping(es, mappingKey)
+
+	for _, hook := range hooks.List(es) {
+		source, ok := mapping[hook.Name]
+		if !ok {
+			source = defaultSource
+		}
+		if source == "" {
+			continue
+		}
+		out[hook.Name] = source
+	}
+
+	return out
+}
+
+// HasKnownSource returns true when an EventSource opted into argoslower managed ingress with either
+// the known source or the mapping annotation.
+func HasKnownSource(es *esv1alpha1.EventSource, annotationKey, mappingKey string) bool {
+	if es == nil {
+		return false
+	}
+
+	_, ok := es.Annotations[annotationKey]
+	_, mok := es.Annotations[mappingKey]
+	return ok || mok
+}
+
+// SourceAllowed reports whether source is permitted by the allowed list. Restricted sources must be
+// listed explicitly while other sources are also permitted by the AllSources wildcard.
+func SourceAllowed(source string, allowed []string, restricted bool) bool {
+	for _, a := range allowed {
+		if a == source || (a == AllSources && !restricted) {
+			return true
+		}
+	}
+	return false
+}
+
+// InferSource returns the known source configured for the EventSource type when every webhook of the
+// EventSource is of that single type. The returned bool is false when no source can be inferred.
+func InferSource(es *esv1alpha1.EventSource, inferred map[hooks.Type]string) (hooks.Type, string, bool) {
+	if es == nil {
+		return "", "", false
+	}
+
+	list := hooks.List(es)
+	if len(list) == 0 {
+		return "", "", false
+	}
+
+	t := list[0].Type
+	for _, hook := range list[1:] {
+		if hook.Type != t {
+			return "", "", false
+		}
+	}
+
+	if hooks.HasUnsupported(es) {
+		return "", "", false
+	}
+
+	source, ok := inferred[t]
+	if !ok || source == "" {
+		return "", "", false
+	}
+
+	return t, source, true
+}
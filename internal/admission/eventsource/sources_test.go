@@ -0,0 +1,153 @@
+package eventsource_test
+
+import (
+	"testing"
+
+	"github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	"github.com/kanopy-platform/argoslower/pkg/hooks"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+func TestHookSources(t *testing.T) {
+	t.Parallel()
+
+	spec := esv1alpha1.EventSourceSpec{
+		Github: map[string]esv1alpha1.GithubEventSource{
+			"gh": esv1alpha1.GithubEventSource{
+				Webhook: &esv1alpha1.WebhookContext{Endpoint: "/gh", Port: "12000"},
+			},
+		},
+		Webhook: map[string]esv1alpha1.WebhookEventSource{
+			"jira": esv1alpha1.WebhookEventSource{
+				WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/jira", Port: "12000"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		annotations map[string]string
+		managed     bool
+		expected    map[string]string
+	}{
+		"no annotations": {
+			expected: map[string]string{},
+		},
+		"default only": {
+			annotations: map[string]string{
+				eventsource.DefaultAnnotationKey: "github",
+			},
+			managed: true,
+			expected: map[string]string{
+				"gh":   "github",
+				"jira": "github",
+			},
+		},
+		"mapping only": {
+			annotations: map[string]string{
+				eventsource.DefaultMappingAnnotationKey: "jira=jira",
+			},
+			managed: true,
+			expected: map[string]string{
+				"jira": "jira",
+			},
+		},
+		"default and mapping": {
+			annotations: map[string]string{
+				eventsource.DefaultAnnotationKey:        "github",
+				eventsource.DefaultMappingAnnotationKey: " jira = jira ,unknown=other",
+			},
+			managed: true,
+			expected: map[string]string{
+				"gh":   "github",
+				"jira": "jira",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		es := &esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{
+				Annotations: test.annotations,
+			},
+			Spec: spec,
+		}
+
+		assert.Equal(t, test.managed, eventsource.HasKnownSource(es, eventsource.DefaultAnnotationKey, eventsource.DefaultMappingAnnotationKey), name)
+		assert.Equal(t, test.expected, eventsource.HookSources(es, eventsource.DefaultAnnotationKey, eventsource.DefaultMappingAnnotationKey), name)
+	}
+}
+
+func TestSourceAllowed(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		source     string
+		allowed    []string
+		restricted bool
+		expected   bool
+	}{
+		"listed":              {source: "github", allowed: []string{"jira", "github"}, expected: true},
+		"unlisted":            {source: "github", allowed: []string{"jira"}},
+		"wildcard":            {source: "github", allowed: []string{eventsource.AllSources}, expected: true},
+		"wildcard restricted": {source: "any", allowed: []string{eventsource.AllSources}, restricted: true},
+		"listed restricted":   {source: "any", allowed: []string{"any"}, restricted: true, expected: true},
+		"empty allowlist":     {source: "github", allowed: []string{}},
+		"nil allowlist":       {source: "github"},
+	}
+
+	for name, test := range tests {
+		assert.Equal(t, test.expected, eventsource.SourceAllowed(test.source, test.allowed, test.restricted), name)
+	}
+}
+
+func TestInferSource(t *testing.T) {
+	t.Parallel()
+
+	webhook := &esv1alpha1.WebhookContext{Endpoint: "/hook", Port: "12000"}
+	inferred := map[hooks.Type]string{hooks.Github: "github"}
+
+	tests := map[string]struct {
+		spec     esv1alpha1.EventSourceSpec
+		t        hooks.Type
+		source   string
+		expected bool
+	}{
+		"none": {},
+		"github": {
+			spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{"a": {Webhook: webhook}, "b": {Webhook: webhook}},
+			},
+			t:        hooks.Github,
+			source:   "github",
+			expected: true,
+		},
+		"github and webhook": {
+			spec: esv1alpha1.EventSourceSpec{
+				Github:  map[string]esv1alpha1.GithubEventSource{"a": {Webhook: webhook}},
+				Webhook: map[string]esv1alpha1.WebhookEventSource{"b": {WebhookContext: *webhook}},
+			},
+		},
+		"github and gitlab": {
+			spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{"a": {Webhook: webhook}},
+				Gitlab: map[string]esv1alpha1.GitlabEventSource{"b": {Webhook: webhook}},
+			},
+		},
+		"unmapped type": {
+			spec: esv1alpha1.EventSourceSpec{
+				Slack: map[string]esv1alpha1.SlackEventSource{"a": {Webhook: webhook}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		ty, source, ok := eventsource.InferSource(&esv1alpha1.EventSource{Spec: test.spec}, inferred)
+		assert.Equal(t, test.expected, ok, name)
+		assert.Equal(t, test.t, ty, name)
+		assert.Equal(t, test.source, source, name)
+	}
+}
@@ -1,10 +1,51 @@
 package testing
 
+import "github.com/kanopy-platform/argoslower/pkg/namespace"
+
+// FakeMeshChecker reports namespaces as sidecar mode when Mesh is set, unless Info provides
+// another dataplane mode.
 type FakeMeshChecker struct {
 	Mesh bool
+	Info namespace.MeshInfo
 	Err  error
 }
 
-func (m *FakeMeshChecker) OnMesh(ns string) (bool, error) {
-	return m.Mesh, m.Err
+func (m *FakeMeshChecker) MeshMode(ns string) (namespace.MeshInfo, error) {
+	if !m.Mesh {
+		return namespace.MeshInfo{}, m.Err
+	}
+
+	if m.Info.Mode == namespace.DataplaneNone {
+		return namespace.MeshInfo{Mode: namespace.DataplaneSidecar}, m.Err
+	}
+
+	return m.Info, m.Err
+}
+
+type FakeSourceAuthorizer struct {
+	Sources map[string][]string
+	Err     error
+}
+
+func (s *FakeSourceAuthorizer) AllowedSources(ns string) ([]string, bool, error) {
+	sources, ok := s.Sources[ns]
+	return sources, ok, s.Err
+}
+
+type FakeStrictExempter struct {
+	Exempt map[string]bool
+	Err    error
+}
+
+func (s *FakeStrictExempter) StrictExempt(ns string) (bool, error) {
+	return s.Exempt[ns], s.Err
+}
+
+type FakeApprovalRequirer struct {
+	Required map[string]bool
+	Err      error
+}
+
+func (a *FakeApprovalRequirer) ApprovalRequired(ns string) (bool, error) {
+	return a.Required[ns], a.Err
 }
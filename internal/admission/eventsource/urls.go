@@ -0,0 +1,54 @@
+package eventsource
+
+import (
+	"fmt"
+	"sort"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+// WebhookURL returns the public url argoslower routes to the webhooks of an EventSource. argo-events
+// registers github webhooks at this url followed by the webhook endpoint.
+func WebhookURL(baseURL, namespace, name string) string {
+	return fmt.Sprintf("https://%s/%s/%s", baseURL, namespace, name)
+}
+
+// SetGithubURLs fills the webhook url of managed github webhooks with the public url of the
+// EventSource. Violations are returned for webhooks configured with a different url.
+func SetGithubURLs(es *esv1alpha1.EventSource, baseURL string, sources map[string]string) []string {
+	violations := []string{}
+	if es == nil || baseURL == "" || es.Name == "" {
+		return violations
+	}
+
+	url := WebhookURL(baseURL, es.Namespace, es.Name)
+
+	names := make([]string, 0, len(es.Spec.Github))
+	for name := range es.Spec.Github {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		spec := es.Spec.Github[name]
+		if spec.Webhook == nil {
+			continue
+		}
+
+		if _, ok := sources[name]; !ok {
+			continue
+		}
+
+		if spec.Webhook.URL == "" {
+			spec.Webhook.URL = url
+			es.Spec.Github[name] = spec
+			continue
+		}
+
+		if spec.Webhook.URL != url {
+			violations = append(violations, fmt.Sprintf("Github webhook %s url %s does not match the managed ingress url %s.", name, spec.Webhook.URL, url))
+		}
+	}
+
+	return violations
+}
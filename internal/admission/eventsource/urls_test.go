@@ -0,0 +1,79 @@
+package eventsource_test
+
+import (
+	"testing"
+
+	"github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+func TestSetGithubURLs(t *testing.T) {
+	t.Parallel()
+
+	const url = "https://webhooks.example.com/foo/bar"
+	assert.Equal(t, url, eventsource.WebhookURL("webhooks.example.com", "foo", "bar"))
+
+	tests := map[string]struct {
+		url        string
+		baseURL    string
+		sources    map[string]string
+		expected   string
+		violations int
+	}{
+		"fill": {
+			baseURL:  "webhooks.example.com",
+			sources:  map[string]string{"gh": "github"},
+			expected: url,
+		},
+		"match": {
+			url:      url,
+			baseURL:  "webhooks.example.com",
+			sources:  map[string]string{"gh": "github"},
+			expected: url,
+		},
+		"mismatch": {
+			url:        "https://other.example.com/hooks",
+			baseURL:    "webhooks.example.com",
+			sources:    map[string]string{"gh": "github"},
+			expected:   "https://other.example.com/hooks",
+			violations: 1,
+		},
+		"trailing slash": {
+			url:        url + "/",
+			baseURL:    "webhooks.example.com",
+			sources:    map[string]string{"gh": "github"},
+			expected:   url + "/",
+			violations: 1,
+		},
+		"unmanaged webhook": {
+			url:      "https://other.example.com/hooks",
+			baseURL:  "webhooks.example.com",
+			sources:  map[string]string{},
+			expected: "https://other.example.com/hooks",
+		},
+		"no base url": {
+			sources: map[string]string{"gh": "github"},
+		},
+	}
+
+	for name, test := range tests {
+		es := &esv1alpha1.EventSource{
+			ObjectMeta: v1.ObjectMeta{Name: "bar", Namespace: "foo"},
+			Spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{
+					"gh":        {Webhook: &esv1alpha1.WebhookContext{Endpoint: "/push", URL: test.url}},
+					"nowebhook": {},
+				},
+			},
+		}
+
+		violations := eventsource.SetGithubURLs(es, test.baseURL, test.sources)
+		assert.Len(t, violations, test.violations, name)
+		assert.Equal(t, test.expected, es.Spec.Github["gh"].Webhook.URL, name)
+		assert.Nil(t, es.Spec.Github["nowebhook"].Webhook, name)
+	}
+}
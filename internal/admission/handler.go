@@ -3,6 +3,7 @@ package admission
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	eventsv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
 	event "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
@@ -12,9 +13,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// ScopeChecker reports whether the objects of a namespace are handled by this instance.
+type ScopeChecker interface {
+	InScope(namespace string) (bool, error)
+}
+
 type RoutingHandler struct {
 	sensorHandler      *sensor.Handler
 	eventSourceHandler *event.Handler
+	scope              ScopeChecker
 }
 
 func NewRoutingHandler(sh *sensor.Handler, es *event.Handler) *RoutingHandler {
@@ -24,12 +31,28 @@ func NewRoutingHandler(sh *sensor.Handler, es *event.Handler) *RoutingHandler {
 	}
 }
 
+// SetScope restricts the handlers to objects of namespaces in scope. Objects of other namespaces are
+// allowed without changes.
+func (h *RoutingHandler) SetScope(s ScopeChecker) {
+	h.scope = s
+}
+
 func (h *RoutingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
 	kind := req.RequestKind
 	if kind == nil {
 		kind = &req.Kind
 	}
 
+	if h.scope != nil {
+		inScope, err := h.scope.InScope(req.Namespace)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if !inScope {
+			return admission.Allowed(fmt.Sprintf("Namespace %s is out of scope", req.Namespace))
+		}
+	}
+
 	switch {
 	case kind.Kind == eventsv1alpha1.EventSourceGroupVersionKind.Kind && h.eventSourceHandler != nil:
 		return h.eventSourceHandler.Handle(ctx, req)
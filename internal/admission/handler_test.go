@@ -3,6 +3,7 @@ package admission
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/kanopy-platform/argoslower/internal/admission/eventsource"
@@ -118,3 +119,46 @@ func TestRoutingHandler(t *testing.T) {
 		assert.Equal(t, !test.esdeny, resp.Allowed, name)
 	}
 }
+
+type fakeScope struct {
+	namespaces map[string]bool
+	err        error
+}
+
+func (f *fakeScope) InScope(namespace string) (bool, error) {
+	return f.namespaces[namespace], f.err
+}
+
+func TestRoutingHandlerScope(t *testing.T) {
+	kind := v1.GroupVersionKind{
+		Kind: eventsv1alpha1.EventSourceGroupVersionKind.Kind,
+	}
+
+	tests := map[string]struct {
+		namespace string
+		scope     *fakeScope
+		allowed   bool
+		code      int32
+	}{
+		"out of scope": {namespace: "other", scope: &fakeScope{namespaces: map[string]bool{"tenant": true}}, allowed: true},
+		"in scope":     {namespace: "tenant", scope: &fakeScope{namespaces: map[string]bool{"tenant": true}}, code: 403},
+		"scope error":  {namespace: "tenant", scope: &fakeScope{err: fmt.Errorf("failed")}, code: 500},
+	}
+
+	for name, test := range tests {
+		// without handlers every object in scope is denied
+		handler := NewRoutingHandler(nil, nil)
+		handler.SetScope(test.scope)
+
+		resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Namespace:   test.namespace,
+			Kind:        kind,
+			RequestKind: &kind,
+		}})
+		assert.Equal(t, test.allowed, resp.Allowed, name)
+		assert.Empty(t, resp.Patches, name)
+		if test.code != 0 {
+			assert.Equal(t, test.code, resp.Result.Code, name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -12,8 +13,12 @@ import (
 	esadd "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
 	sadd "github.com/kanopy-platform/argoslower/internal/admission/sensor"
 	esctrl "github.com/kanopy-platform/argoslower/internal/controllers/eventsource"
+	"github.com/kanopy-platform/argoslower/pkg/hooks"
+	ingressv1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+	"github.com/kanopy-platform/argoslower/pkg/ingress/v1/gatewayapi"
 	ic "github.com/kanopy-platform/argoslower/pkg/ingress/v1/istio"
 	"github.com/kanopy-platform/argoslower/pkg/iplister"
+	awsc "github.com/kanopy-platform/argoslower/pkg/iplister/clients/aws"
 	ghc "github.com/kanopy-platform/argoslower/pkg/iplister/clients/github"
 	filedecoder "github.com/kanopy-platform/argoslower/pkg/iplister/decoder/file"
 	"github.com/kanopy-platform/argoslower/pkg/iplister/decoder/officeips"
@@ -27,10 +32,13 @@ import (
 	"github.com/spf13/viper"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -39,12 +47,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	klog "sigs.k8s.io/controller-runtime/pkg/log"
 	k8szap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -52,6 +63,7 @@ import (
 	eventsv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
 	eventsclient "github.com/argoproj/argo-events/pkg/client/clientset/versioned"
 	eventsinformer "github.com/argoproj/argo-events/pkg/client/informers/externalversions"
+	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
 
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
 	istioinformer "istio.io/client-go/pkg/informers/externalversions"
@@ -97,8 +109,35 @@ func NewRootCommand() *cobra.Command {
 	cmd.PersistentFlags().String("admin-namespace", "routing", "Ingress controller admin namespace")
 	cmd.PersistentFlags().String("gateway-namespace", "routing-rules", "Namespace of the ingress gateway")
 	cmd.PersistentFlags().String("gateway-name", "argo-webhook-gateway", "Name of the ingress gateway")
+	cmd.PersistentFlags().String("ingress-provider", "istio", "Implementation configuring the ingress of EventSources. One of istio or gatewayapi")
 	cmd.PersistentFlags().String("gateway-selector", "istio=istio-ingressgateway-public", "Label selector for the ingress gateway as a key=value comma delimited string")
-	cmd.PersistentFlags().String("supported-hooks", "github=github", "comma separated key=value list used for assigning IPGetters for various hook annotations")
+	cmd.PersistentFlags().String("allowed-sources-annotation", "v1alpha1.argoslower.kanopy-platform/allowed-known-sources", "Namespace annotation listing the known webhook sources allowed in the namespace as a comma delimited string")
+	cmd.PersistentFlags().String("default-allowed-sources", "*", "Comma delimited list of known webhook sources allowed in namespaces without the allowed sources annotation. * allows every source not backed by the any provider")
+	cmd.PersistentFlags().String("mesh-injection-label", namespace.DefaultInjectionLabel, "Namespace key=value label enabling sidecar injection. Empty disables detection")
+	cmd.PersistentFlags().String("mesh-revision-label", namespace.DefaultRevisionLabel, "Namespace label selecting the istio revision or revision tag for sidecar injection. Empty disables detection")
+	cmd.PersistentFlags().String("mesh-ambient-label", namespace.DefaultAmbientLabel, "Namespace key=value label enrolling the namespace in ambient mode. Empty disables detection")
+	cmd.PersistentFlags().Bool("strict-webhook-ingress", false, "Deny webhook EventSources without a known source annotation and EventSource services reachable outside of the cluster")
+	cmd.PersistentFlags().String("strict-exempt-annotation", "v1alpha1.argoslower.kanopy-platform/unmanaged-webhooks", "Namespace annotation exempting the namespace from strict webhook ingress when set to true")
+	cmd.PersistentFlags().String("inferred-sources", "github=github", "comma separated type=source list defaulting the known source annotation of EventSources whose webhooks are all of the EventSource type, i.e. github, slack, sns or webhook")
+	cmd.PersistentFlags().Bool("generate-webhook-secrets", false, "Generate secrets for webhooks without an authSecret and github webhooks without a webhookSecret instead of denying the EventSource")
+	cmd.PersistentFlags().String("secret-validation", string(esadd.SecretValidationOff), "Validate webhook secrets referenced by EventSources at admission. One of off, warn or deny")
+	cmd.PersistentFlags().Float64("min-secret-entropy", esadd.DefaultMinSecretEntropy, "Minimum estimated entropy of webhook secrets in bits")
+	cmd.PersistentFlags().String("approval-groups", "", "Comma delimited list of groups whose members may approve webhook endpoints. Empty disables the approval workflow")
+	cmd.PersistentFlags().String("approval-required-annotation", "v1alpha1.argoslower.kanopy-platform/require-ingress-approval", "Namespace annotation requiring approval of webhook endpoints before ingress is configured when set to true")
+	cmd.PersistentFlags().Duration("stuck-finalizer-timeout", time.Hour, "Release the ingress finalizer of deleted EventSources whose ingress could not be removed within the timeout. 0 waits for the removal to succeed")
+	cmd.PersistentFlags().String("watch-namespaces", "", "Comma delimited list of namespaces whose EventSources and Sensors are handled. Empty handles every namespace")
+	cmd.PersistentFlags().String("namespace-selector", "", "Label selector of the namespaces whose EventSources and Sensors are handled. Empty handles every namespace")
+	cmd.PersistentFlags().String("known-source-annotation", esadd.DefaultAnnotationKey, "EventSource annotation assigning the known source of its webhooks")
+	cmd.PersistentFlags().String("known-source-mapping-annotation", esadd.DefaultMappingAnnotationKey, "EventSource annotation assigning known sources to individual webhooks")
+	cmd.PersistentFlags().String("ingress-status-annotation", esctrl.DefaultStatusAnnotationKey, "EventSource annotation reporting the ingress status")
+	cmd.PersistentFlags().String("ingress-finalizer", esctrl.DefaultFinalizer, "Finalizer holding managed EventSources until their ingress is removed")
+	cmd.PersistentFlags().Int("max-concurrent-reconciles", 1, "Maximum number of EventSources reconciled concurrently")
+	cmd.PersistentFlags().Duration("reconcile-base-delay", esctrl.DefaultRateLimiterOptions().BaseDelay, "Initial delay before retrying a failed EventSource reconciliation, doubled on each consecutive failure")
+	cmd.PersistentFlags().Duration("reconcile-max-delay", esctrl.DefaultRateLimiterOptions().MaxDelay, "Maximum delay before retrying a failed EventSource reconciliation")
+	cmd.PersistentFlags().Float64("reconcile-qps", esctrl.DefaultRateLimiterOptions().QPS, "Overall rate of EventSource reconciliation retries per second")
+	cmd.PersistentFlags().Int("reconcile-burst", esctrl.DefaultRateLimiterOptions().Burst, "Burst of EventSource reconciliation retries above the reconcile-qps rate")
+	cmd.PersistentFlags().Duration("resync-period", time.Minute, "Resync period of the informers, every EventSource is reconciled at least once per period")
+	cmd.PersistentFlags().String("supported-hooks", "github=github", "comma separated key=value list used for assigning IPGetters for various hook annotations. The aws provider accepts optional service and region filters as aws:SERVICE:REGION")
 
 	k8sFlags.AddFlags(cmd.PersistentFlags())
 	// no need to check err, this only checks if variadic args != 0
@@ -183,12 +222,10 @@ func (c *RootCommand) runE(cmd *cobra.Command, args []string) error {
 	}
 
 	esc := eventsclient.NewForConfigOrDie(cfg)
-	esinformerFactory := eventsinformer.NewSharedInformerFactoryWithOptions(esc, 1*time.Minute)
-
-	esi := esinformerFactory.Argoproj().V1alpha1().EventSources()
+	resync := viper.GetDuration("resync-period")
 
 	k8sClientSet := kubernetes.NewForConfigOrDie(cfg)
-	k8sInformerFactory := informers.NewSharedInformerFactoryWithOptions(k8sClientSet, 1*time.Minute)
+	k8sInformerFactory := informers.NewSharedInformerFactoryWithOptions(k8sClientSet, resync)
 
 	namespacesInformer := k8sInformerFactory.Core().V1().Namespaces()
 	_, err = namespacesInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -201,10 +238,52 @@ func (c *RootCommand) runE(cmd *cobra.Command, args []string) error {
 	k8sInformerFactory.Start(wait.NeverStop)
 	k8sInformerFactory.WaitForCacheSync(wait.NeverStop)
 
+	namespaceSelector, err := labels.Parse(viper.GetString("namespace-selector"))
+	if err != nil {
+		return fmt.Errorf("invalid namespace-selector: %w", err)
+	}
+
+	scope, err := namespace.NewScope(stringutils.SplitTrim(viper.GetString("watch-namespaces"), ","), namespaceSelector, namespacesInformer.Lister())
+	if err != nil {
+		return err
+	}
+	// namespaces created together with their EventSources may be missing from the informer cache
+	scope.SetNamespaceGetter(k8sClientSet.CoreV1().Namespaces())
+
+	// EventSource informers are restricted to the watched namespaces, one informer per namespace
+	var esLister eslister.EventSourceLister
+	esInformers := []cache.SharedIndexInformer{}
+	esInformerFactories := []eventsinformer.SharedInformerFactory{}
+	if namespaces := scope.Namespaces(); len(namespaces) > 0 {
+		listers := esctrl.NamespacedEventSourceLister{}
+		for _, ns := range namespaces {
+			factory := eventsinformer.NewSharedInformerFactoryWithOptions(esc, resync, eventsinformer.WithNamespace(ns))
+			esi := factory.Argoproj().V1alpha1().EventSources()
+			listers[ns] = esi.Lister()
+			esInformers = append(esInformers, esi.Informer())
+			esInformerFactories = append(esInformerFactories, factory)
+		}
+		esLister = listers
+	} else {
+		factory := eventsinformer.NewSharedInformerFactoryWithOptions(esc, resync)
+		esi := factory.Argoproj().V1alpha1().EventSources()
+		esLister = esi.Lister()
+		esInformers = append(esInformers, esi.Informer())
+		esInformerFactories = append(esInformerFactories, factory)
+	}
+
 	rlua := viper.GetString("rate-limit-unit-annotation")
 	rlra := viper.GetString("requests-per-unit-annotation")
 
 	nsInformer := namespace.NewNamespaceInfo(namespacesInformer.Lister(), rlua, rlra)
+	nsInformer.SetAllowedSourcesAnnotation(viper.GetString("allowed-sources-annotation"))
+	nsInformer.SetStrictExemptAnnotation(viper.GetString("strict-exempt-annotation"))
+	nsInformer.SetApprovalAnnotation(viper.GetString("approval-required-annotation"))
+	nsInformer.SetMeshDetectors(namespace.NewMeshDetectors(
+		viper.GetString("mesh-injection-label"),
+		viper.GetString("mesh-revision-label"),
+		viper.GetString("mesh-ambient-label"),
+	)...)
 
 	drlu := viper.GetString("default-rate-limit-unit")
 	drlr := viper.GetInt32("default-requests-per-unit")
@@ -230,7 +309,7 @@ func (c *RootCommand) runE(cmd *cobra.Command, args []string) error {
 			opts.LabelSelector = selector
 		})
 
-		filteredk8sInformerFactory := informers.NewSharedInformerFactoryWithOptions(k8sClientSet, 1*time.Minute, labelOptions)
+		filteredk8sInformerFactory := informers.NewSharedInformerFactoryWithOptions(k8sClientSet, resync, labelOptions)
 
 		filteredServiceInfomer := filteredk8sInformerFactory.Core().V1().Services()
 		_, err = filteredServiceInfomer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -240,40 +319,88 @@ func (c *RootCommand) runE(cmd *cobra.Command, args []string) error {
 			klog.Log.Error(err, "unable to add event handler to the filtered service informer")
 		}
 
-		filteredk8sInformerFactory.Start(wait.NeverStop)
-		filteredk8sInformerFactory.WaitForCacheSync(wait.NeverStop)
-
-		istioCS := istioclient.NewForConfigOrDie(cfg)
-		istioOptions := istioinformer.WithTweakListOptions(func(opts *metav1.ListOptions) {
-			opts.LabelSelector = selector
-		})
-		filteredIstioInformerFactory := istioinformer.NewSharedInformerFactoryWithOptions(istioCS, 1*time.Minute, istioOptions)
-
-		filteredVirtualServiceInfomer := filteredIstioInformerFactory.Networking().V1beta1().VirtualServices().Informer()
-		_, err = filteredVirtualServiceInfomer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		// EndpointSlices inherit the labels of their Service and are used for resolving named targetPorts
+		filteredEndpointSliceInformer := filteredk8sInformerFactory.Discovery().V1().EndpointSlices()
+		_, err = filteredEndpointSliceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 			AddFunc: func(new interface{}) {},
 		})
 		if err != nil {
-			klog.Log.Error(err, "unable to add event handler to the filtered virtualService informer")
+			klog.Log.Error(err, "unable to add event handler to the filtered endpointslice informer")
 		}
 
-		filteredAuthorizationPolicyInformer := filteredIstioInformerFactory.Security().V1beta1().AuthorizationPolicies().Informer()
-		_, err = filteredAuthorizationPolicyInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc: func(new interface{}) {},
-		})
-		if err != nil {
-			klog.Log.Error(err, "unable to add event handler to the filtered authorizationPolicy informer")
-		}
+		filteredk8sInformerFactory.Start(wait.NeverStop)
+		filteredk8sInformerFactory.WaitForCacheSync(wait.NeverStop)
+
+		// generated ingress resources are watched through the informers of the ingress provider
+		var ingressClient ingressv1.IngressConfigurator
+		var ingressInformers []cache.SharedIndexInformer
+
+		switch provider := viper.GetString("ingress-provider"); provider {
+		case "istio":
+			istioCS := istioclient.NewForConfigOrDie(cfg)
+			istioOptions := istioinformer.WithTweakListOptions(func(opts *metav1.ListOptions) {
+				opts.LabelSelector = selector
+			})
+			filteredIstioInformerFactory := istioinformer.NewSharedInformerFactoryWithOptions(istioCS, resync, istioOptions)
+
+			filteredVirtualServices := filteredIstioInformerFactory.Networking().V1beta1().VirtualServices()
+			filteredVirtualServiceInfomer := filteredVirtualServices.Informer()
+			_, err = filteredVirtualServiceInfomer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc: func(new interface{}) {},
+			})
+			if err != nil {
+				klog.Log.Error(err, "unable to add event handler to the filtered virtualService informer")
+			}
+
+			filteredAuthorizationPolicies := filteredIstioInformerFactory.Security().V1beta1().AuthorizationPolicies()
+			filteredAuthorizationPolicyInformer := filteredAuthorizationPolicies.Informer()
+			_, err = filteredAuthorizationPolicyInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc: func(new interface{}) {},
+			})
+			if err != nil {
+				klog.Log.Error(err, "unable to add event handler to the filtered authorizationPolicy informer")
+			}
+
+			filteredIstioInformerFactory.Start(wait.NeverStop)
+			filteredIstioInformerFactory.WaitForCacheSync(wait.NeverStop)
+
+			gws := stringutils.StringToMap(viper.GetString("gateway-selector"), ",", "=")
+			if len(gws) == 0 {
+				return fmt.Errorf("invalid gateway-selector: %s", viper.GetString("gateway-selector"))
+			}
+
+			istioClient := ic.NewClient(istioCS, gws)
+			istioClient.SetListers(filteredVirtualServices.Lister(), filteredAuthorizationPolicies.Lister())
+			ingressClient = istioClient
+			ingressInformers = []cache.SharedIndexInformer{filteredVirtualServiceInfomer, filteredAuthorizationPolicyInformer}
+		case "gatewayapi":
+			dc := dynamic.NewForConfigOrDie(cfg)
+			filteredDynamicInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dc, resync, metav1.NamespaceAll, func(opts *metav1.ListOptions) {
+				opts.LabelSelector = selector
+			})
+
+			gatewayClient := gatewayapi.NewClient(dc)
+			for _, gvr := range []schema.GroupVersionResource{gatewayapi.HTTPRouteResource, gatewayapi.AuthorizationPolicyResource, gatewayapi.ReferenceGrantResource} {
+				generic := filteredDynamicInformerFactory.ForResource(gvr)
+				gatewayClient.SetLister(gvr, generic.Lister())
+				informer := generic.Informer()
+				_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc: func(new interface{}) {},
+				})
+				if err != nil {
+					klog.Log.Error(err, "unable to add event handler to the filtered informer", "resource", gvr.String())
+				}
+				ingressInformers = append(ingressInformers, informer)
+			}
 
-		filteredIstioInformerFactory.Start(wait.NeverStop)
-		filteredIstioInformerFactory.WaitForCacheSync(wait.NeverStop)
+			filteredDynamicInformerFactory.Start(wait.NeverStop)
+			filteredDynamicInformerFactory.WaitForCacheSync(wait.NeverStop)
 
-		gws := stringutils.StringToMap(viper.GetString("gateway-selector"), ",", "=")
-		if len(gws) == 0 {
-			return fmt.Errorf("invalid gateway-selector: %s", viper.GetString("gateway-selector"))
+			ingressClient = gatewayClient
+		default:
+			return fmt.Errorf("invalid ingress-provider: %s", provider)
 		}
 
-		ingressClient := ic.NewClient(istioCS, gws)
 		escc := esctrl.NewEventSourceIngressControllerConfig()
 		escc.Gateway = types.NamespacedName{
 			Namespace: viper.GetString("gateway-namespace"),
@@ -283,7 +410,25 @@ func (c *RootCommand) runE(cmd *cobra.Command, args []string) error {
 		escc.BaseURL = viper.GetString("webhook-url")
 		escc.AdminNamespace = viper.GetString("admin-namespace")
 
-		esController := esctrl.NewEventSourceIngressController(esi.Lister(), filteredServiceInfomer.Lister(), escc, ingressClient)
+		esController := esctrl.NewEventSourceIngressController(esLister, filteredServiceInfomer.Lister(), escc, ingressClient)
+		esController.SetScope(scope)
+		esController.SetMeshChecker(nsInformer)
+		esController.SetAnnotationKey(viper.GetString("known-source-annotation"))
+		esController.SetMappingAnnotationKey(viper.GetString("known-source-mapping-annotation"))
+		esController.SetStatusAnnotationKey(viper.GetString("ingress-status-annotation"))
+		esController.SetFinalizer(viper.GetString("ingress-finalizer"))
+		esController.SetEndpointSliceLister(filteredEndpointSliceInformer.Lister())
+		if viper.GetBool("generate-webhook-secrets") {
+			esController.SetSecretClient(k8sClientSet.CoreV1())
+		}
+		esController.SetEventRecorder(mgr.GetEventRecorderFor("argoslower"))
+		esController.SetEventSourceClient(esc.ArgoprojV1alpha1())
+		esController.SetStuckFinalizerTimeout(viper.GetDuration("stuck-finalizer-timeout"))
+
+		approvalGroups := stringutils.SplitTrim(viper.GetString("approval-groups"), ",")
+		if len(approvalGroups) > 0 {
+			esController.SetApprovalRequirer(nsInformer)
+		}
 
 		hookConfig := stringutils.StringToMap(viper.GetString("supported-hooks"), ",", "=")
 		err = configureHooks(esController, hookConfig)
@@ -292,37 +437,132 @@ func (c *RootCommand) runE(cmd *cobra.Command, args []string) error {
 		}
 
 		eventSourceHandler = esadd.NewHandler(nsInformer, escc.GetKnownSources())
-		err = eventSourceHandler.InjectDecoder(admission.NewDecoder(mgr.GetScheme()))
+		eventSourceHandler.SetAnnotationKey(viper.GetString("known-source-annotation"))
+		eventSourceHandler.SetMappingAnnotationKey(viper.GetString("known-source-mapping-annotation"))
+		eventSourceHandler.SetStatusAnnotationKey(viper.GetString("ingress-status-annotation"))
+		eventSourceHandler.SetSourceAuthorizer(nsInformer)
+		eventSourceHandler.SetDefaultSources(stringutils.SplitTrim(viper.GetString("default-allowed-sources"), ","))
+		eventSourceHandler.SetRestrictedSources(escc.GetRestrictedSources())
+		eventSourceHandler.SetStrict(viper.GetBool("strict-webhook-ingress"), nsInformer)
+
+		inferredSources := map[hooks.Type]string{}
+		for t, source := range stringutils.StringToMap(viper.GetString("inferred-sources"), ",", "=") {
+			inferredSources[hooks.Type(t)] = source
+		}
+		eventSourceHandler.SetInferredSources(inferredSources)
+		eventSourceHandler.SetGenerateSecrets(viper.GetBool("generate-webhook-secrets"))
+		eventSourceHandler.SetBaseURL(escc.BaseURL)
+		eventSourceHandler.SetApproval(approvalGroups, nsInformer)
+
+		secretValidation, err := esadd.ParseSecretValidationMode(viper.GetString("secret-validation"))
 		if err != nil {
 			return err
 		}
 
-		_, err = esi.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc: func(new interface{}) {}})
+		// referenced secrets are read with direct GETs instead of caching every secret in the cluster
+		eventSourceHandler.SetSecretValidation(secretValidation, k8sClientSet.CoreV1(), viper.GetFloat64("min-secret-entropy"))
+		err = eventSourceHandler.InjectDecoder(admission.NewDecoder(mgr.GetScheme()))
 		if err != nil {
-			klog.Log.Error(err, "unable to add event handler to the esi informer")
+			return err
+		}
+
+		for _, informer := range esInformers {
+			_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc: func(new interface{}) {}})
+			if err != nil {
+				klog.Log.Error(err, "unable to add event handler to the esi informer")
+			}
 		}
 
-		esinformerFactory.Start(wait.NeverStop)
-		esinformerFactory.WaitForCacheSync(wait.NeverStop)
+		for _, factory := range esInformerFactories {
+			factory.Start(wait.NeverStop)
+			factory.WaitForCacheSync(wait.NeverStop)
+		}
+
+		rateLimiter, err := esctrl.NewRateLimiter(esctrl.RateLimiterOptions{
+			BaseDelay: viper.GetDuration("reconcile-base-delay"),
+			MaxDelay:  viper.GetDuration("reconcile-max-delay"),
+			QPS:       viper.GetFloat64("reconcile-qps"),
+			Burst:     viper.GetInt("reconcile-burst"),
+		})
+		if err != nil {
+			return err
+		}
 
 		ctrl, err := controller.New("argoslower-eventsource-controller", mgr, controller.Options{
-			Reconciler: esController,
+			Reconciler:              esController,
+			MaxConcurrentReconciles: viper.GetInt("max-concurrent-reconciles"),
+			RateLimiter:             rateLimiter,
 		})
 
 		if err != nil {
 			return err
 		}
 
+		for _, informer := range esInformers {
+			if e := ctrl.Watch(&source.Informer{
+				Informer: informer,
+				Handler:  &handler.EnqueueRequestForObject{},
+			}); e != nil {
+				return e
+			}
+		}
+
+		// EventSources are reconciled when the mesh enrollment labels of their namespace change
 		if e := ctrl.Watch(&source.Informer{
-			Informer: esi.Informer(),
-			Handler:  &handler.EnqueueRequestForObject{},
+			Informer:   namespacesInformer.Informer(),
+			Handler:    esController.EnqueueForNamespace(),
+			Predicates: []predicate.Predicate{predicate.LabelChangedPredicate{}},
 		}); e != nil {
 			return e
 		}
+
+		// EventSources are reconciled when the CIDRs of their known sources change. The watchers are
+		// started by the leader like the channel source, standby replicas would block on sending events
+		ipEvents := make(chan event.GenericEvent)
+		if e := ctrl.Watch(source.Channel(ipEvents, &handler.EnqueueRequestForObject{})); e != nil {
+			return e
+		}
+
+		err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			esController.WatchIPGetters(ctx, ipEvents)
+			<-ctx.Done()
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+
+		// ingress left behind by EventSources deleted or opted out while the controller was down and
+		// finalizers of EventSources that left the scope
+		err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			if err := esController.CollectGarbage(ctx); err != nil {
+				setupLog.Error(err, "unable to collect orphaned ingress resources")
+			}
+			if err := esController.ReleaseOutOfScope(ctx); err != nil {
+				setupLog.Error(err, "unable to release the finalizer of eventsources out of scope")
+			}
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+
+		// generated ingress resources and EventSource Services are mapped back to their EventSource
+		// through the eventsource labels so edits, deletions and late Services reconcile immediately
+		for _, informer := range append([]cache.SharedIndexInformer{filteredServiceInfomer.Informer()}, ingressInformers...) {
+			if e := ctrl.Watch(&source.Informer{
+				Informer: informer,
+				Handler:  esctrl.EnqueueForEventSource(),
+			}); e != nil {
+				return e
+			}
+		}
 	}
 
-	add.NewRoutingHandler(sensorHandler, eventSourceHandler).SetupWithManager(mgr)
+	routingHandler := add.NewRoutingHandler(sensorHandler, eventSourceHandler)
+	routingHandler.SetScope(scope)
+	routingHandler.SetupWithManager(mgr)
 
 	return mgr.Start(ctx)
 }
@@ -334,6 +574,11 @@ func configureHooks(esic *esctrl.EventSourceIngressController, config map[string
 		if hook == "" {
 			continue
 		}
+
+		// providers may carry colon delimited arguments, i.e. aws:AMAZON:us-east-1
+		args := strings.Split(provider, ":")
+		provider = args[0]
+
 		switch provider {
 		case "github":
 			githubGetter := ghc.New()
@@ -354,6 +599,17 @@ func configureHooks(esic *esctrl.EventSourceIngressController, config map[string
 			g := iplister.New(h, d)
 			c := iplister.NewCachedIPLister(g)
 			esic.SetIPGetter(hook, c)
+		case "aws":
+			var service, region string
+			if len(args) > 1 {
+				service = args[1]
+			}
+			if len(args) > 2 {
+				region = args[2]
+			}
+			g := awsc.New(service, region)
+			c := iplister.NewCachedIPLister(g)
+			esic.SetIPGetter(hook, c)
 		case "any":
 			klog.Log.V(1).Info(fmt.Sprintf("The any provider is only designed for debug and testing use. Configuring for hook type: %s", hook))
 			g := &iplister.AnyGetter{}
@@ -0,0 +1,48 @@
+package eventsource
+
+import (
+	"fmt"
+
+	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	"k8s.io/client-go/tools/record"
+)
+
+// ReasonAwaitingApproval is the event reason of EventSources whose ingress is withheld until the webhook
+// endpoints are approved.
+const ReasonAwaitingApproval string = "AwaitingApproval"
+
+// SetApprovalRequirer enables withholding ingress of EventSources in namespaces requiring approval until
+// the current webhook endpoints are approved.
+func (e *EventSourceIngressController) SetApprovalRequirer(ar eshandler.ApprovalRequirer) {
+	e.approvalRequirer = ar
+}
+
+// SetEventRecorder configures the recorder used for emitting events on EventSources.
+func (e *EventSourceIngressController) SetEventRecorder(recorder record.EventRecorder) {
+	e.recorder = recorder
+}
+
+// awaitingApproval reports whether the ingress of an EventSource requires an approval of its current
+// webhook endpoints that has not been given.
+func (e *EventSourceIngressController) awaitingApproval(es *esv1alpha1.EventSource, sources map[string]string) (bool, error) {
+	if e.approvalRequirer == nil {
+		return false, nil
+	}
+
+	required, err := e.approvalRequirer.ApprovalRequired(es.Namespace)
+	if err != nil {
+		return false, perrs.NewRetryableError(fmt.Errorf("unable to look up the approval requirement of namespace %s: %w", es.Namespace, err))
+	}
+
+	return required && !eshandler.Approved(es, sources), nil
+}
+
+func (e *EventSourceIngressController) event(es *esv1alpha1.EventSource, eventType, reason, message string) {
+	if e.recorder == nil {
+		return
+	}
+	e.recorder.Event(es, eventType, reason, message)
+}
@@ -0,0 +1,115 @@
+package eventsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	estest "github.com/kanopy-platform/argoslower/internal/admission/eventsource/testing"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReconcileApproval(t *testing.T) {
+	t.Parallel()
+
+	newEventSource := func(namespace, approval string) *esv1alpha1.EventSource {
+		es := &esv1alpha1.EventSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "es",
+				Namespace:   namespace,
+				Annotations: map[string]string{eshandler.DefaultAnnotationKey: "github"},
+			},
+			Spec: esv1alpha1.EventSourceSpec{
+				Github: map[string]esv1alpha1.GithubEventSource{
+					"ghs": {
+						WebhookSecret: &corev1.SecretKeySelector{Key: "secret"},
+						Webhook:       &esv1alpha1.WebhookContext{Endpoint: "/push", Port: "12000"},
+					},
+				},
+			},
+		}
+		if approval != "" {
+			es.Annotations[eshandler.DefaultApprovalAnnotationKey] = approval
+		}
+		return es
+	}
+
+	hash := eshandler.ApprovalHash(newEventSource("regulated", ""), map[string]string{"ghs": "github"})
+
+	tests := []struct {
+		name        string
+		es          *esv1alpha1.EventSource
+		requireErr  error
+		wantRemoved bool
+		wantEvent   bool
+		wantErr     bool
+	}{
+		{name: "awaiting approval", es: newEventSource("regulated", ""), wantRemoved: true, wantEvent: true},
+		{name: "stale approval", es: newEventSource("regulated", "stale"), wantRemoved: true, wantEvent: true},
+		{name: "approved", es: newEventSource("regulated", hash)},
+		{name: "approval not required", es: newEventSource("default", "")},
+		{name: "requirer error", es: newEventSource("regulated", hash), requireErr: errors.New("test error"), wantErr: true},
+	}
+
+	for _, test := range tests {
+		svcl := &FakeServiceNamespaceLister{}
+		svcl.AppendService(&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "es-eventsource-svc", Namespace: test.es.Namespace},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 12000, TargetPort: intstr.FromInt32(12000)}},
+			},
+		})
+		sl := &FakeServiceLister{}
+		sl.SetNamespaceLister(svcl)
+
+		config := NewEventSourceIngressControllerConfig()
+		config.Gateway = types.NamespacedName{Name: "gateway", Namespace: "routing"}
+		config.AdminNamespace = "routing"
+		config.BaseURL = "webhooks.example.com"
+		config.SetIPGetter("github", &FakeIPGetter{})
+
+		igc := &FakeConfigurator{}
+		recorder := record.NewFakeRecorder(10)
+
+		controller := NewEventSourceIngressController(&FakeESLister{}, sl, config, igc)
+		controller.SetApprovalRequirer(&estest.FakeApprovalRequirer{Required: map[string]bool{"regulated": true}, Err: test.requireErr})
+		controller.SetEventRecorder(recorder)
+
+		status := &IngressStatus{}
+		err := controller.reconcile(context.TODO(), test.es, types.NamespacedName{Namespace: test.es.Namespace, Name: test.es.Name}, status)
+		if test.wantErr {
+			assert.Error(t, err, test.name)
+			assert.Equal(t, ReasonApprovalLookupFailed, status.Reason, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+
+		if test.wantRemoved {
+			assert.Equal(t, 1, igc.removed, test.name)
+			assert.Equal(t, 0, igc.configured, test.name)
+			assert.Equal(t, ReasonAwaitingApproval, status.Reason, test.name)
+			assert.False(t, status.Ready, test.name)
+		} else {
+			assert.Equal(t, 0, igc.removed, test.name)
+			assert.Equal(t, 1, igc.configured, test.name)
+			assert.Equal(t, ReasonConfigured, status.Reason, test.name)
+			assert.True(t, status.Ready, test.name)
+			assert.Equal(t, "https://webhooks.example.com/"+test.es.Namespace+"/es", status.URL, test.name)
+			assert.Equal(t, 1, status.AllowedCIDRs, test.name)
+		}
+
+		if test.wantEvent {
+			assert.Contains(t, <-recorder.Events, ReasonAwaitingApproval, test.name)
+		} else {
+			assert.Empty(t, recorder.Events, test.name)
+		}
+	}
+}
@@ -4,31 +4,52 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
 	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+	"github.com/kanopy-platform/argoslower/pkg/hooks"
 	ingresscommon "github.com/kanopy-platform/argoslower/pkg/ingress"
 	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+	"github.com/kanopy-platform/argoslower/pkg/iplister"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corev1lister "k8s.io/client-go/listers/core/v1"
+	discoverylister "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/record"
 
 	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	esclient "github.com/argoproj/argo-events/pkg/client/clientset/versioned/typed/events/v1alpha1"
 	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/labels"
 )
 
 type EventSourceIngressController struct {
-	esLister      eslister.EventSourceLister
-	serviceLister corev1lister.ServiceLister
-	igc           v1.IngressConfigurator
-	config        EventSourceIngressControllerConfig
+	esLister              eslister.EventSourceLister
+	serviceLister         corev1lister.ServiceLister
+	endpointSliceLister   discoverylister.EndpointSliceLister
+	igc                   v1.IngressConfigurator
+	secretClient          corev1client.SecretsGetter
+	approvalRequirer      eshandler.ApprovalRequirer
+	meshChecker           eshandler.MeshChecker
+	recorder              record.EventRecorder
+	esClient              esclient.EventSourcesGetter
+	finalizer             string
+	stuckFinalizerTimeout time.Duration
+	annotationKey         string
+	mappingAnnotationKey  string
+	statusAnnotationKey   string
+	scope                 ScopeChecker
+	config                EventSourceIngressControllerConfig
 }
 
 type EventSourceIngressControllerConfig struct {
@@ -59,15 +80,56 @@ func (c *EventSourceIngressControllerConfig) GetKnownSources() map[string]bool {
 	return knownSources
 }
 
+// GetRestrictedSources returns the known sources backed by the any provider. These sources accept
+// traffic from every address and must be explicitly allowed per namespace.
+func (c *EventSourceIngressControllerConfig) GetRestrictedSources() map[string]bool {
+	restricted := make(map[string]bool)
+	for source, getter := range c.ipGetters {
+		switch getter.(type) {
+		case iplister.AnyGetter, *iplister.AnyGetter:
+			restricted[source] = true
+		}
+	}
+	return restricted
+}
+
 func NewEventSourceIngressController(esl eslister.EventSourceLister, svcl corev1lister.ServiceLister, config EventSourceIngressControllerConfig, igc v1.IngressConfigurator) *EventSourceIngressController {
 	return &EventSourceIngressController{
-		esLister:      esl,
-		serviceLister: svcl,
-		config:        config,
-		igc:           igc,
+		esLister:             esl,
+		serviceLister:        svcl,
+		config:               config,
+		igc:                  igc,
+		finalizer:            DefaultFinalizer,
+		annotationKey:        eshandler.DefaultAnnotationKey,
+		mappingAnnotationKey: eshandler.DefaultMappingAnnotationKey,
+		statusAnnotationKey:  DefaultStatusAnnotationKey,
+	}
+}
+
+// SetAnnotationKey configures the EventSource annotation assigning the known source of its webhooks.
+func (e *EventSourceIngressController) SetAnnotationKey(key string) {
+	if key != "" {
+		e.annotationKey = key
 	}
 }
 
+// SetMappingAnnotationKey configures the EventSource annotation assigning known sources to individual webhooks.
+func (e *EventSourceIngressController) SetMappingAnnotationKey(key string) {
+	if key != "" {
+		e.mappingAnnotationKey = key
+	}
+}
+
+// hookSources returns the known source of every webhook of an EventSource.
+func (e *EventSourceIngressController) hookSources(es *esv1alpha1.EventSource) map[string]string {
+	return eshandler.HookSources(es, e.annotationKey, e.mappingAnnotationKey)
+}
+
+// SetEndpointSliceLister configures the lister used for resolving named Service targetPorts
+func (e *EventSourceIngressController) SetEndpointSliceLister(l discoverylister.EndpointSliceLister) {
+	e.endpointSliceLister = l
+}
+
 func (e *EventSourceIngressController) SetIPGetter(name string, getter v1.IPGetter) {
 	if e.config.ipGetters == nil {
 		e.config.ipGetters = map[string]v1.IPGetter{}
@@ -79,6 +141,32 @@ func (e *EventSourceIngressController) SetIPGetter(name string, getter v1.IPGett
 func (e *EventSourceIngressController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
+	// EventSources of namespaces out of scope are left to other instances
+	inScope, err := e.inScope(req.Namespace)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to look up the scope of eventsource %v", req))
+		return ctrl.Result{
+			Requeue: true,
+		}, err
+	}
+	if !inScope {
+		log.V(1).Info(fmt.Sprintf("Skipping eventsource %v out of scope", req))
+		eventSource, err := e.esLister.EventSources(req.Namespace).Get(req.Name)
+		if k8serror.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		if err == nil {
+			err = e.releaseOutOfScope(ctx, eventSource.DeepCopy())
+		}
+		if err != nil {
+			log.Error(err, fmt.Sprintf("unable to release the finalizer of eventsource %v out of scope", req))
+			return ctrl.Result{
+				Requeue: true,
+			}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	eventSource, err := e.esLister.EventSources(req.Namespace).Get(req.Name)
 	if err != nil && !k8serror.IsNotFound(err) {
 		log.Error(err, fmt.Sprintf("unable to get eventsource %v", req))
@@ -87,12 +175,33 @@ func (e *EventSourceIngressController) Reconcile(ctx context.Context, req ctrl.R
 		}, err
 	}
 
-	if err = e.reconcile(ctx, eventSource.DeepCopy(), req.NamespacedName); err != nil {
+	status := &IngressStatus{}
+	err = e.reconcile(ctx, eventSource.DeepCopy(), req.NamespacedName, status)
+	if serr := e.recordStatus(ctx, eventSource, *status, time.Now()); serr != nil {
+		log.Error(serr, fmt.Sprintf("unable to record the ingress status of eventsource %v", req))
+		if err == nil {
+			err = serr
+		}
+	}
+
+	if err != nil {
+		reconcileErrors.WithLabelValues(errorReason(status, err)).Inc()
+
 		var retry bool
 		rerr, ok := err.(*perrs.RetryableError)
 		if ok {
 			retry = rerr.IsRetryable()
 		}
+
+		// controller-runtime ignores the result of failed reconciliations, hinted retries are
+		// logged here and requeued without an error
+		if after := perrs.RetryAfterOf(err); retry && after > 0 {
+			log.Error(err, fmt.Sprintf("unable to reconcile eventsource %v, retrying in %s", req, after))
+			return ctrl.Result{
+				RequeueAfter: after,
+			}, nil
+		}
+
 		return ctrl.Result{
 			Requeue: retry,
 		}, err
@@ -102,7 +211,8 @@ func (e *EventSourceIngressController) Reconcile(ctx context.Context, req ctrl.R
 
 }
 
-func (e *EventSourceIngressController) reconcile(ctx context.Context, es *esv1alpha1.EventSource, nsn types.NamespacedName) error {
+// reconcile configures or removes the ingress of an EventSource and records the outcome in status.
+func (e *EventSourceIngressController) reconcile(ctx context.Context, es *esv1alpha1.EventSource, nsn types.NamespacedName, status *IngressStatus) error {
 	log := log.FromContext(ctx)
 	log.V(5).Info("Starting reconciliation for %s/%s", nsn.Namespace, nsn.Name)
 
@@ -116,9 +226,55 @@ func (e *EventSourceIngressController) reconcile(ctx context.Context, es *esv1al
 		return e.igc.Remove(ctx, &esiConfig)
 	}
 
-	hookType, ok := es.Annotations[eshandler.DefaultAnnotationKey]
-	if !ok {
-		//TODO: should this get filtered at admission time for a list of supported values?
+	if es.DeletionTimestamp != nil {
+		return e.finalize(ctx, es, &esiConfig)
+	}
+
+	if !eshandler.HasKnownSource(es, e.annotationKey, e.mappingAnnotationKey) {
+		// ingress of EventSources that opted out of managed ingress is torn down
+		if err := e.igc.Remove(ctx, &esiConfig); err != nil {
+			return err
+		}
+		return e.releaseFinalizer(ctx, es)
+	}
+
+	if err := e.ensureFinalizer(ctx, es); err != nil {
+		return status.fail(ReasonFinalizerFailed, err)
+	}
+	if err := e.ensureGeneratedSecret(ctx, es); err != nil {
+		return status.fail(ReasonSecretGenerationFailed, err)
+	}
+
+	sources := e.hookSources(es)
+
+	offMesh, err := e.offMesh(es.Namespace)
+	if err != nil {
+		return status.fail(ReasonMeshLookupFailed, err)
+	}
+
+	if offMesh {
+		// public traffic must not reach pods that are not protected by the mesh
+		log.Info(fmt.Sprintf("EventSource %s namespace is not enrolled in the mesh, removing ingress", nsn.String()))
+		status.Reason = ReasonNamespaceOffMesh
+		status.LastError = fmt.Sprintf("Namespace %s is not enrolled in the mesh, managed ingress is removed", es.Namespace)
+		if err := e.igc.Remove(ctx, &esiConfig); err != nil {
+			return status.fail(ReasonRemoveFailed, err)
+		}
+		return nil
+	}
+
+	awaiting, err := e.awaitingApproval(es, sources)
+	if err != nil {
+		return status.fail(ReasonApprovalLookupFailed, err)
+	}
+
+	if awaiting {
+		log.Info(fmt.Sprintf("EventSource %s ingress is awaiting approval", nsn.String()))
+		e.event(es, corev1.EventTypeNormal, ReasonAwaitingApproval, "Managed ingress is awaiting approval of the webhook endpoints")
+		status.Reason = ReasonAwaitingApproval
+		if err := e.igc.Remove(ctx, &esiConfig); err != nil {
+			return status.fail(ReasonRemoveFailed, err)
+		}
 		return nil
 	}
 
@@ -128,23 +284,25 @@ func (e *EventSourceIngressController) reconcile(ctx context.Context, es *esv1al
 		}),
 	)
 	if err != nil {
-		return perrs.NewUnretryableError(err)
+		return status.fail(ReasonServiceNotFound, perrs.NewUnretryableError(err))
 	}
 
 	log.V(5).Info("Sourcing service for eventsource  %s/%s", nsn.Namespace, nsn.Name)
 	svcl, err := e.serviceLister.Services(nsn.Namespace).List(selector)
 	if err != nil {
-		return perrs.NewRetryableError(err)
+		return status.fail(ReasonServiceNotFound, perrs.NewRetryableError(err))
 	}
 
 	if len(svcl) != 1 {
-		return perrs.NewRetryableError(fmt.Errorf("cannot select a service for event source %s/%s. Want 1 received: %d", nsn.Namespace, nsn.Name, len(svcl)))
+		// argo-events creates the service shortly after the EventSource
+		err := fmt.Errorf("cannot select a service for event source %s/%s. Want 1 received: %d", nsn.Namespace, nsn.Name, len(svcl))
+		return status.fail(ReasonServiceNotFound, perrs.NewRetryableError(err, perrs.WithRetryAfter(serviceRetryAfter)))
 	}
 
 	svc := svcl[0]
 	if svc == nil {
 		//This might not be retryable if the api is returning a nil service but we will requeue for now
-		return perrs.NewRetryableError(fmt.Errorf("expected a Service resource and got: %v", svc))
+		return status.fail(ReasonServiceNotFound, perrs.NewRetryableError(fmt.Errorf("expected a Service resource and got: %v", svc)))
 	}
 
 	esiConfig.Service = types.NamespacedName{
@@ -153,63 +311,251 @@ func (e *EventSourceIngressController) reconcile(ctx context.Context, es *esv1al
 	}
 
 	// Populate the Service to EventSource lookup map
-	esiConfig.Endpoints = ServiceToPortMapping(svc, es)
-	if len(esiConfig.Endpoints) == 0 {
-		//TODO: we might want to emit an event here for a misconfigured eventsource
+	endpoints, unmatched := ServiceToPortMapping(svc, es, e.resolveNamedPort)
+	for _, err := range unmatched {
 		// the port on the webhook configuration doesn't appear on the service definition
 		// it is excluded because it isn't routable
-		msg := fmt.Sprintf("Unabled to map eventsource  %s.service to endpoints.", nsn.String())
-		return perrs.NewUnretryableError(errors.New(msg))
+		log.Info(fmt.Sprintf("EventSource %s webhook excluded from ingress: %s", nsn.String(), err.Error()))
+		e.event(es, corev1.EventTypeWarning, ReasonWebhookExcluded, err.Error())
+		status.Excluded = append(status.Excluded, err.Error())
+	}
+
+	if len(endpoints) == 0 {
+		err := fmt.Errorf("no webhooks of eventsource %s are exposed by service %s", nsn.String(), esiConfig.Service.String())
+		for _, u := range unmatched {
+			if re, ok := u.(*perrs.RetryableError); ok && re.IsRetryable() {
+				return status.fail(ReasonNoExposedWebhooks, perrs.NewRetryableError(errors.Join(append([]error{err}, unmatched...)...)))
+			}
+		}
+		return status.fail(ReasonNoExposedWebhooks, perrs.NewUnretryableError(errors.Join(append([]error{err}, unmatched...)...)))
 	}
 
-	ipGetter, ok := e.config.ipGetters[hookType]
-	if !ok {
-		msg := fmt.Sprintf("EventSource %s Hook type: %s, not supported", nsn.String(), hookType)
-		return perrs.NewUnretryableError(errors.New(msg))
+	endpoints, unsupported := e.assignSources(endpoints, sources)
+	for _, err := range unsupported {
+		log.Info(fmt.Sprintf("EventSource %s webhook excluded from ingress: %s", nsn.String(), err.Error()))
+		e.event(es, corev1.EventTypeWarning, ReasonWebhookExcluded, err.Error())
+		status.Excluded = append(status.Excluded, err.Error())
+	}
+
+	if len(endpoints) == 0 {
+		msg := fmt.Sprintf("EventSource %s has no webhooks with a supported hook type", nsn.String())
+		err := perrs.NewUnretryableError(errors.Join(append([]error{errors.New(msg)}, unsupported...)...))
+		if rerr := e.igc.Remove(ctx, &esiConfig); rerr != nil {
+			return status.fail(ReasonRemoveFailed, perrs.NewRetryableError(errors.Join(err, rerr)))
+		}
+		return status.fail(ReasonUnsupportedHookType, err)
+	}
+
+	esiConfig.Endpoints = endpoints
+	esiConfig.IPGetters = map[string]v1.IPGetter{}
+	for _, paths := range endpoints {
+		for _, path := range paths {
+			esiConfig.IPGetters[path.Source] = e.config.ipGetters[path.Source]
+		}
+	}
+
+	cidrs, err := countCIDRs(esiConfig.IPGetters)
+	if err != nil {
+		return status.fail(ReasonIPFetchFailed, err)
 	}
 
-	esiConfig.IPGetter = ipGetter
 	names, err := e.igc.Configure(ctx, &esiConfig)
 	log.V(5).Info("Created resources %s", names)
-	return err
+	if err != nil {
+		return status.fail(ReasonConfigureFailed, err)
+	}
+
+	status.Ready = true
+	status.Reason = ReasonConfigured
+	status.URL = eshandler.WebhookURL(e.config.BaseURL, nsn.Namespace, nsn.Name)
+	status.AllowedCIDRs = cidrs
+	return nil
 }
 
+// countCIDRs returns the number of CIDRs allowed by the IPGetters of the known sources. IPGetters
+// cache their CIDRs, the ingress configurator sources the same CIDRs afterwards. Failures are retried
+// after the retry hint of the provider, or after ipRetryAfter as the cache only refreshes periodically.
+func countCIDRs(getters map[string]v1.IPGetter) (int, error) {
+	count := 0
+	for source, getter := range getters {
+		if getter == nil {
+			continue
+		}
+
+		ips, err := getter.GetIPs()
+		if err == nil && len(ips) == 0 {
+			err = fmt.Errorf("no CIDRs")
+		}
+
+		if err != nil {
+			after := perrs.RetryAfterOf(err)
+			if after == 0 {
+				after = ipRetryAfter
+			}
+			return 0, perrs.NewRetryableError(fmt.Errorf("failed to source IPs from %s: %w", source, err), perrs.WithRetryAfter(after))
+		}
+		count += len(ips)
+	}
+	return count, nil
+}
+
+// assignSources sets the known source of each endpoint from the per webhook source lookup. Endpoints without
+// a source or with a source that has no configured IPGetter are excluded and returned as individual errors.
+func (e *EventSourceIngressController) assignSources(in map[string][]ingresscommon.NamedPath, sources map[string]string) (map[string][]ingresscommon.NamedPath, []error) {
+	out := map[string][]ingresscommon.NamedPath{}
+	var unsupported []error
+
+	for port, paths := range in {
+		for _, path := range paths {
+			source, ok := sources[path.Name]
+			if !ok {
+				unsupported = append(unsupported, perrs.NewUnretryableError(fmt.Errorf("webhook %s has no known source", path.Name)))
+				continue
+			}
+
+			if _, ok := e.config.ipGetters[source]; !ok {
+				unsupported = append(unsupported, perrs.NewUnretryableError(fmt.Errorf("webhook %s Hook type: %s, not supported", path.Name, source)))
+				continue
+			}
+
+			path.Source = source
+			out[port] = append(out[port], path)
+		}
+	}
+
+	out, nested := excludeNestedEndpoints(out)
+	return out, append(unsupported, nested...)
+}
+
+// excludeNestedEndpoints excludes endpoints that are a prefix of an endpoint with a different known source,
+// the ingress of an endpoint also matches its sub paths and would deny requests from the nested source.
+// Excluded endpoints are returned as individual errors.
+func excludeNestedEndpoints(in map[string][]ingresscommon.NamedPath) (map[string][]ingresscommon.NamedPath, []error) {
+	out := map[string][]ingresscommon.NamedPath{}
+	var nested []error
+
+	for port, parents := range in {
+		for _, parent := range parents {
+			var err error
+			for _, paths := range in {
+				for _, path := range paths {
+					if path.Source != parent.Source && ingresscommon.NestedEndpoint(parent.Path, path.Path) {
+						err = fmt.Errorf("webhook %s endpoint %s with known source %s is a prefix of webhook %s endpoint %s with known source %s", parent.Name, parent.Path, parent.Source, path.Name, path.Path, path.Source)
+						break
+					}
+				}
+				if err != nil {
+					break
+				}
+			}
+
+			if err != nil {
+				nested = append(nested, perrs.NewUnretryableError(err))
+				continue
+			}
+			out[port] = append(out[port], parent)
+		}
+	}
+
+	return out, nested
+}
+
+// NamedPortResolver resolves the container port number a named Service targetPort refers to.
+type NamedPortResolver func(svc *corev1.Service, port corev1.ServicePort) (int32, error)
+
 // ServiceToPortMapping - receives a Service and argo EventSource and returns a validated port lookup map of
-// NamedPaths. The lookup map allows mapping a target port to a desired path. It is generic for any eventsource
-// but only provides data for supported event source types, github and webhook currently.
-func ServiceToPortMapping(svc *corev1.Service, es *esv1alpha1.EventSource) (out map[string]ingresscommon.NamedPath) {
-	out = map[string]ingresscommon.NamedPath{}
-	//Only github and webhook eventsources are supported for self-service webhooks currently.
-	//if neither of those are configured don't offer any ports
+// NamedPaths. The lookup map allows mapping a service port to the desired paths served on it, several webhooks
+// may share a port with different endpoints. Webhook ports are container ports and are matched against the
+// service targetPort, named targetPorts are resolved with the optional resolver. Webhooks that are not
+// exposed by the service are returned as individual errors. It is generic for any eventsource but only
+// provides data for supported event source types, github, slack, sns and webhook currently.
+func ServiceToPortMapping(svc *corev1.Service, es *esv1alpha1.EventSource, resolve NamedPortResolver) (out map[string][]ingresscommon.NamedPath, unmatched []error) {
+	out = map[string][]ingresscommon.NamedPath{}
+	//Only supported hook types are offered for self-service webhooks currently.
+	//if none of those are configured don't offer any ports
 	if svc == nil || es == nil {
-		return out
+		return out, unmatched
 	}
 
+	// container port to service port lookup
+	ports := map[string]string{}
+	var resolveErrs []error
 	for _, svcport := range svc.Spec.Ports {
-		out[fmt.Sprintf("%d", svcport.Port)] = ingresscommon.NamedPath{}
-	}
-	for esn, spec := range es.Spec.Webhook {
-		np, ok := out[spec.Port]
-		if !ok {
+		target := svcport.Port
+		switch {
+		case svcport.TargetPort.Type == intstr.String:
+			if resolve == nil {
+				resolveErrs = append(resolveErrs, fmt.Errorf("service port %s has a named targetPort %s that cannot be resolved", svcport.Name, svcport.TargetPort.StrVal))
+				continue
+			}
+			resolved, err := resolve(svc, svcport)
+			if err != nil {
+				resolveErrs = append(resolveErrs, err)
+				continue
+			}
+			target = resolved
+		case svcport.TargetPort.IntVal != 0:
+			target = svcport.TargetPort.IntVal
+		}
+
+		containerPort := fmt.Sprintf("%d", target)
+		if _, ok := ports[containerPort]; ok {
 			continue
 		}
-		np.Name = esn
-		np.Path = spec.Endpoint
-		out[spec.Port] = np
+		ports[containerPort] = fmt.Sprintf("%d", svcport.Port)
 	}
 
-	for esn, spec := range es.Spec.Github {
-		if spec.Webhook == nil {
+	// webhooks share the /namespace/name prefix on the ingress host, every endpoint may only be routed once
+	paths := map[string]hooks.Hook{}
+	for _, hook := range hooks.List(es) {
+		path := ingresscommon.NormalizeEndpoint(hook.Context.Endpoint)
+		if other, ok := paths[path]; ok {
+			unmatched = append(unmatched, perrs.NewUnretryableError(fmt.Errorf("%s webhook %s endpoint %s collides with %s webhook %s", hook.Type, hook.Name, path, other.Type, other.Name)))
 			continue
 		}
-		np, ok := out[spec.Webhook.Port]
+		paths[path] = hook
+
+		svcPort, ok := ports[hook.Context.Port]
 		if !ok {
+			err := fmt.Errorf("%s webhook %s port %s is not a targetPort of service %s/%s", hook.Type, hook.Name, hook.Context.Port, svc.Namespace, svc.Name)
+			if len(resolveErrs) > 0 {
+				// the port might be exposed by a named port that failed to resolve
+				err = perrs.NewRetryableError(errors.Join(append([]error{err}, resolveErrs...)...))
+			} else {
+				err = perrs.NewUnretryableError(err)
+			}
+			unmatched = append(unmatched, err)
 			continue
 		}
-		np.Name = esn
-		np.Path = spec.Webhook.Endpoint
-		out[spec.Webhook.Port] = np
+		out[svcPort] = append(out[svcPort], ingresscommon.NamedPath{
+			Name: hook.Name,
+			Path: path,
+		})
+	}
+
+	return out, unmatched
+}
+
+// resolveNamedPort looks up the port number for a named Service targetPort from the EndpointSlices
+// of the Service which carry the resolved container port for each service port name.
+func (e *EventSourceIngressController) resolveNamedPort(svc *corev1.Service, port corev1.ServicePort) (int32, error) {
+	if e.endpointSliceLister == nil {
+		return 0, fmt.Errorf("unable to resolve named targetPort %s of service %s/%s, no EndpointSlice lister configured", port.TargetPort.StrVal, svc.Namespace, svc.Name)
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: svc.Name})
+	slices, err := e.endpointSliceLister.EndpointSlices(svc.Namespace).List(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, slice := range slices {
+		for _, p := range slice.Ports {
+			if p.Name != nil && *p.Name == port.Name && p.Port != nil {
+				return *p.Port, nil
+			}
+		}
 	}
 
-	return out
+	return 0, fmt.Errorf("unable to resolve named targetPort %s of service %s/%s", port.TargetPort.StrVal, svc.Namespace, svc.Name)
 }
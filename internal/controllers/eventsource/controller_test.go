@@ -7,26 +7,36 @@ import (
 	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
 	ingresscommon "github.com/kanopy-platform/argoslower/pkg/ingress"
 	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+	"github.com/kanopy-platform/argoslower/pkg/iplister"
 
 	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
 	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	corev1lister "k8s.io/client-go/listers/core/v1"
+	discoverylister "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type FakeConfigurator struct {
+	configured int
+	removed    int
 }
 
 func (c *FakeConfigurator) Configure(ctx context.Context, config *v1.EventSourceIngressConfig) ([]types.NamespacedName, error) {
+	c.configured++
 	return []types.NamespacedName{}, nil
 }
 
 func (c *FakeConfigurator) Remove(ctx context.Context, config *v1.EventSourceIngressConfig) error {
+	c.removed++
 	return nil
 }
 
@@ -212,16 +222,19 @@ func (f *FakeServiceNamespaceLister) Next() (*corev1.Service, error) {
 
 func TestServiceToPortMapping(t *testing.T) {
 	tests := []struct {
-		name     string
-		svc      *corev1.Service
-		es       *esv1alpha1.EventSource
-		expected map[string]ingresscommon.NamedPath
+		name      string
+		svc       *corev1.Service
+		es        *esv1alpha1.EventSource
+		resolver  NamedPortResolver
+		expected  map[string][]ingresscommon.NamedPath
+		unmatched int
+		retryable bool
 	}{
 		{
 			name:     "empty",
 			svc:      &corev1.Service{},
 			es:       &esv1alpha1.EventSource{},
-			expected: map[string]ingresscommon.NamedPath{},
+			expected: map[string][]ingresscommon.NamedPath{},
 		},
 		{
 			name: "webhook",
@@ -246,10 +259,12 @@ func TestServiceToPortMapping(t *testing.T) {
 					},
 				},
 			},
-			expected: map[string]ingresscommon.NamedPath{
-				"12345": ingresscommon.NamedPath{
-					Name: "thing",
-					Path: "/path",
+			expected: map[string][]ingresscommon.NamedPath{
+				"12345": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "thing",
+						Path: "/path",
+					},
 				},
 			},
 		},
@@ -278,23 +293,220 @@ func TestServiceToPortMapping(t *testing.T) {
 						},
 						"thingTwo": esv1alpha1.WebhookEventSource{
 							WebhookContext: esv1alpha1.WebhookContext{
-								Endpoint: "/path",
+								Endpoint: "/other",
 								Port:     "54321",
 							},
 						},
 					},
 				},
 			},
-			expected: map[string]ingresscommon.NamedPath{
-				"12345": ingresscommon.NamedPath{
-					Name: "thingOne",
-					Path: "/path",
+			expected: map[string][]ingresscommon.NamedPath{
+				"12345": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "thingOne",
+						Path: "/path",
+					},
+				},
+				"54321": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "thingTwo",
+						Path: "/other",
+					},
+				},
+			},
+		},
+		{
+			name: "multiple webhooks on one port",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						corev1.ServicePort{
+							Port: int32(12345),
+						},
+						corev1.ServicePort{
+							Port: int32(54321),
+						},
+					},
+				},
+			},
+			es: &esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"thingOne": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								Endpoint: "/one",
+								Port:     "12345",
+							},
+						},
+						"thingTwo": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								Endpoint: "/two",
+								Port:     "12345",
+							},
+						},
+					},
+				},
+			},
+			expected: map[string][]ingresscommon.NamedPath{
+				"12345": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "thingOne",
+						Path: "/one",
+					},
+					ingresscommon.NamedPath{
+						Name: "thingTwo",
+						Path: "/two",
+					},
+				},
+			},
+		},
+		{
+			name: "target port",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						corev1.ServicePort{
+							Port:       int32(80),
+							TargetPort: intstr.FromInt32(8080),
+						},
+					},
+				},
+			},
+			es: &esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"thing": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								Endpoint: "/path",
+								Port:     "8080",
+							},
+						},
+						"unexposed": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								Endpoint: "/other",
+								Port:     "80",
+							},
+						},
+					},
+				},
+			},
+			expected: map[string][]ingresscommon.NamedPath{
+				"80": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "thing",
+						Path: "/path",
+					},
+				},
+			},
+			unmatched: 1,
+		},
+		{
+			name: "named target port",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						corev1.ServicePort{
+							Name:       "http",
+							Port:       int32(80),
+							TargetPort: intstr.FromString("webhook"),
+						},
+					},
+				},
+			},
+			es: &esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"thing": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								Endpoint: "/path",
+								Port:     "12000",
+							},
+						},
+					},
+				},
+			},
+			resolver: func(svc *corev1.Service, port corev1.ServicePort) (int32, error) {
+				return 12000, nil
+			},
+			expected: map[string][]ingresscommon.NamedPath{
+				"80": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "thing",
+						Path: "/path",
+					},
+				},
+			},
+		},
+		{
+			name: "unresolved named target port",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						corev1.ServicePort{
+							Name:       "http",
+							Port:       int32(80),
+							TargetPort: intstr.FromString("webhook"),
+						},
+					},
+				},
+			},
+			es: &esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"thing": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								Endpoint: "/path",
+								Port:     "12000",
+							},
+						},
+					},
+				},
+			},
+			expected:  map[string][]ingresscommon.NamedPath{},
+			unmatched: 1,
+			retryable: true,
+		},
+		{
+			name: "colliding endpoints",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						corev1.ServicePort{
+							Port: int32(12000),
+						},
+						corev1.ServicePort{
+							Port: int32(13000),
+						},
+					},
+				},
+			},
+			es: &esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Webhook: map[string]esv1alpha1.WebhookEventSource{
+						"a": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								Endpoint: "path",
+								Port:     "12000",
+							},
+						},
+						"b": esv1alpha1.WebhookEventSource{
+							WebhookContext: esv1alpha1.WebhookContext{
+								Endpoint: "/path",
+								Port:     "13000",
+							},
+						},
+					},
 				},
-				"54321": ingresscommon.NamedPath{
-					Name: "thingTwo",
-					Path: "/path",
+			},
+			expected: map[string][]ingresscommon.NamedPath{
+				"12000": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "a",
+						Path: "/path",
+					},
 				},
 			},
+			unmatched: 1,
 		},
 		{
 			name: "github",
@@ -319,23 +531,74 @@ func TestServiceToPortMapping(t *testing.T) {
 					},
 				},
 			},
-			expected: map[string]ingresscommon.NamedPath{
-				"12345": ingresscommon.NamedPath{
-					Name: "github",
-					Path: "/path",
+			expected: map[string][]ingresscommon.NamedPath{
+				"12345": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "github",
+						Path: "/path",
+					},
+				},
+			},
+		},
+		{
+			name: "slack and unsupported stripe",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						corev1.ServicePort{
+							Port: int32(12345),
+						},
+						corev1.ServicePort{
+							Port: int32(54321),
+						},
+					},
+				},
+			},
+			es: &esv1alpha1.EventSource{
+				Spec: esv1alpha1.EventSourceSpec{
+					Slack: map[string]esv1alpha1.SlackEventSource{
+						"slack": esv1alpha1.SlackEventSource{
+							Webhook: &esv1alpha1.WebhookContext{
+								Endpoint: "/slack",
+								Port:     "12345",
+							},
+						},
+					},
+					Stripe: map[string]esv1alpha1.StripeEventSource{
+						"stripe": esv1alpha1.StripeEventSource{
+							Webhook: &esv1alpha1.WebhookContext{
+								Endpoint: "/stripe",
+								Port:     "54321",
+							},
+						},
+						"nowebhook": esv1alpha1.StripeEventSource{},
+					},
+				},
+			},
+			expected: map[string][]ingresscommon.NamedPath{
+				"12345": []ingresscommon.NamedPath{
+					ingresscommon.NamedPath{
+						Name: "slack",
+						Path: "/slack",
+					},
 				},
 			},
 		},
 	}
 
 	for _, test := range tests {
-		out := ServiceToPortMapping(test.svc, test.es)
+		out, unmatched := ServiceToPortMapping(test.svc, test.es, test.resolver)
 		assert.Equal(t, len(test.expected), len(out), test.name)
+		assert.Len(t, unmatched, test.unmatched, test.name)
+		for _, err := range unmatched {
+			re, ok := err.(*perrs.RetryableError)
+			require.True(t, ok, test.name)
+			assert.Equal(t, test.retryable, re.IsRetryable(), test.name)
+		}
 		for k, v := range test.expected {
 			val, ok := out[k]
 			assert.True(t, ok, test.name)
-			assert.Equal(t, v.Name, val.Name, test.name)
-			assert.Equal(t, v.Path, val.Path, test.name)
+			assert.Equal(t, v, val, test.name)
 		}
 
 	}
@@ -365,3 +628,138 @@ func TestGetKnownSources(t *testing.T) {
 
 	assert.Equal(t, expectedSources, knownSources)
 }
+
+func TestGetRestrictedSources(t *testing.T) {
+	escConfig := NewEventSourceIngressControllerConfig()
+
+	escConfig.SetIPGetter("github", &FakeIPGetter{})
+	escConfig.SetIPGetter("debug", &iplister.AnyGetter{})
+	escConfig.SetIPGetter("test", iplister.AnyGetter{})
+
+	expectedSources := map[string]bool{
+		"debug": true,
+		"test":  true,
+	}
+
+	assert.Equal(t, expectedSources, escConfig.GetRestrictedSources())
+}
+
+func TestResolveNamedPort(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+	name := "http"
+	port := int32(12000)
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bar-eventsource-svc-abcde",
+			Namespace: "foo",
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: "bar-eventsource-svc",
+			},
+		},
+		Ports: []discoveryv1.EndpointPort{
+			discoveryv1.EndpointPort{
+				Name: &name,
+				Port: &port,
+			},
+		},
+	}
+	require.NoError(t, indexer.Add(slice))
+
+	controller := NewEventSourceIngressController(&FakeESLister{}, &FakeServiceLister{}, NewEventSourceIngressControllerConfig(), &FakeConfigurator{})
+
+	svc := &corev1.Service{}
+	svc.Name = "bar-eventsource-svc"
+	svc.Namespace = "foo"
+	svcPort := corev1.ServicePort{
+		Name:       "http",
+		Port:       int32(80),
+		TargetPort: intstr.FromString("webhook"),
+	}
+
+	_, err := controller.resolveNamedPort(svc, svcPort)
+	assert.Error(t, err)
+
+	controller.SetEndpointSliceLister(discoverylister.NewEndpointSliceLister(indexer))
+
+	resolved, err := controller.resolveNamedPort(svc, svcPort)
+	assert.NoError(t, err)
+	assert.Equal(t, port, resolved)
+
+	svcPort.Name = "other"
+	_, err = controller.resolveNamedPort(svc, svcPort)
+	assert.Error(t, err)
+}
+
+func TestAssignSources(t *testing.T) {
+	config := NewEventSourceIngressControllerConfig()
+	config.SetIPGetter("github", &FakeIPGetter{})
+	config.SetIPGetter("jira", &FakeIPGetter{})
+
+	controller := NewEventSourceIngressController(&FakeESLister{}, &FakeServiceLister{}, config, &FakeConfigurator{})
+
+	in := map[string][]ingresscommon.NamedPath{
+		"80": []ingresscommon.NamedPath{
+			ingresscommon.NamedPath{Name: "github", Path: "/github"},
+			ingresscommon.NamedPath{Name: "jira", Path: "/jira"},
+		},
+		"8080": []ingresscommon.NamedPath{
+			ingresscommon.NamedPath{Name: "unmapped", Path: "/unmapped"},
+			ingresscommon.NamedPath{Name: "unsupported", Path: "/unsupported"},
+		},
+	}
+
+	sources := map[string]string{
+		"github":      "github",
+		"jira":        "jira",
+		"unsupported": "officeips",
+	}
+
+	out, unsupported := controller.assignSources(in, sources)
+	assert.Len(t, unsupported, 2)
+	assert.Equal(t, map[string][]ingresscommon.NamedPath{
+		"80": []ingresscommon.NamedPath{
+			ingresscommon.NamedPath{Name: "github", Path: "/github", Source: "github"},
+			ingresscommon.NamedPath{Name: "jira", Path: "/jira", Source: "jira"},
+		},
+	}, out)
+}
+
+func TestAssignSourcesNested(t *testing.T) {
+	config := NewEventSourceIngressControllerConfig()
+	config.SetIPGetter("github", &FakeIPGetter{})
+	config.SetIPGetter("jira", &FakeIPGetter{})
+
+	controller := NewEventSourceIngressController(&FakeESLister{}, &FakeServiceLister{}, config, &FakeConfigurator{})
+
+	in := map[string][]ingresscommon.NamedPath{
+		"80": []ingresscommon.NamedPath{
+			ingresscommon.NamedPath{Name: "push", Path: "/push"},
+			ingresscommon.NamedPath{Name: "pushed", Path: "/pushed"},
+		},
+		"8080": []ingresscommon.NamedPath{
+			ingresscommon.NamedPath{Name: "jira", Path: "/push/jira"},
+			ingresscommon.NamedPath{Name: "github", Path: "/pushed/github"},
+		},
+	}
+
+	sources := map[string]string{
+		"push":   "github",
+		"pushed": "github",
+		"jira":   "jira",
+		"github": "github",
+	}
+
+	out, unsupported := controller.assignSources(in, sources)
+	require.Len(t, unsupported, 1)
+	assert.Contains(t, unsupported[0].Error(), "webhook push endpoint /push with known source github is a prefix of webhook jira")
+	assert.Equal(t, map[string][]ingresscommon.NamedPath{
+		"80": []ingresscommon.NamedPath{
+			ingresscommon.NamedPath{Name: "pushed", Path: "/pushed", Source: "github"},
+		},
+		"8080": []ingresscommon.NamedPath{
+			ingresscommon.NamedPath{Name: "jira", Path: "/push/jira", Source: "jira"},
+			ingresscommon.NamedPath{Name: "github", Path: "/pushed/github", Source: "github"},
+		},
+	}, out)
+}
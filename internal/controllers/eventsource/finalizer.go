@@ -0,0 +1,117 @@
+package eventsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultFinalizer holds managed EventSources until their generated ingress resources are removed.
+const DefaultFinalizer string = "argoslower.kanopy-platform/ingress"
+
+// ReasonFinalizerReleased is the event reason of EventSources whose finalizer was released although
+// their ingress could not be removed.
+const ReasonFinalizerReleased string = "FinalizerReleased"
+
+// ReasonFinalizerFailed is the status reason of EventSources the finalizer could not be added to.
+const ReasonFinalizerFailed string = "FinalizerFailed"
+
+// SetFinalizer configures the finalizer holding managed EventSources until their ingress is removed.
+// Instances managing EventSources of the same namespaces require distinct finalizers.
+func (e *EventSourceIngressController) SetFinalizer(name string) {
+	if name != "" {
+		e.finalizer = name
+	}
+}
+
+// SetStuckFinalizerTimeout configures how long a deleted EventSource is held by the finalizer while
+// its ingress cannot be removed. Zero holds it until the removal succeeds or fails permanently.
+func (e *EventSourceIngressController) SetStuckFinalizerTimeout(timeout time.Duration) {
+	e.stuckFinalizerTimeout = timeout
+}
+
+// finalize removes the ingress of a deleted EventSource and releases the finalizer afterwards. The
+// finalizer is also released when the removal fails permanently or the EventSource is stuck in
+// deletion for longer than the stuck finalizer timeout, leaving the ingress to the startup sweep.
+func (e *EventSourceIngressController) finalize(ctx context.Context, es *esv1alpha1.EventSource, config *v1.EventSourceIngressConfig) error {
+	log := log.FromContext(ctx)
+
+	err := e.igc.Remove(ctx, config)
+	if !controllerutil.ContainsFinalizer(es, e.finalizer) {
+		return err
+	}
+
+	if err != nil {
+		rerr, ok := err.(*perrs.RetryableError)
+		if ok && rerr.IsRetryable() && !e.stuck(es, time.Now()) {
+			return err
+		}
+
+		msg := fmt.Sprintf("Releasing finalizer %s, the ingress could not be removed: %s", e.finalizer, err.Error())
+		log.Info(fmt.Sprintf("EventSource %s/%s: %s", es.Namespace, es.Name, msg))
+		e.event(es, corev1.EventTypeWarning, ReasonFinalizerReleased, msg)
+	}
+
+	return e.patchFinalizers(ctx, es, slices.DeleteFunc(slices.Clone(es.Finalizers), func(f string) bool { return f == e.finalizer }))
+}
+
+// stuck reports whether a deleted EventSource has been held longer than the stuck finalizer timeout.
+func (e *EventSourceIngressController) stuck(es *esv1alpha1.EventSource, now time.Time) bool {
+	if e.stuckFinalizerTimeout <= 0 || es.DeletionTimestamp == nil {
+		return false
+	}
+	return now.Sub(es.DeletionTimestamp.Time) >= e.stuckFinalizerTimeout
+}
+
+// ensureFinalizer adds the finalizer to a managed EventSource.
+func (e *EventSourceIngressController) ensureFinalizer(ctx context.Context, es *esv1alpha1.EventSource) error {
+	if controllerutil.ContainsFinalizer(es, e.finalizer) {
+		return nil
+	}
+	return e.patchFinalizers(ctx, es, append(slices.Clone(es.Finalizers), e.finalizer))
+}
+
+// releaseFinalizer removes the finalizer from an EventSource without managed ingress.
+func (e *EventSourceIngressController) releaseFinalizer(ctx context.Context, es *esv1alpha1.EventSource) error {
+	if !controllerutil.ContainsFinalizer(es, e.finalizer) {
+		return nil
+	}
+	return e.patchFinalizers(ctx, es, slices.DeleteFunc(slices.Clone(es.Finalizers), func(f string) bool { return f == e.finalizer }))
+}
+
+// patchFinalizers replaces the finalizers of an EventSource. The resourceVersion guards against
+// overwriting finalizers changed since the EventSource was listed. Finalizers are not managed
+// without an EventSource client.
+func (e *EventSourceIngressController) patchFinalizers(ctx context.Context, es *esv1alpha1.EventSource, finalizers []string) error {
+	if e.esClient == nil {
+		return nil
+	}
+
+	metadata := map[string]interface{}{"finalizers": finalizers}
+	if es.ResourceVersion != "" {
+		metadata["resourceVersion"] = es.ResourceVersion
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		return perrs.NewUnretryableError(err)
+	}
+
+	if _, err := e.esClient.EventSources(es.Namespace).Patch(ctx, es.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return perrs.NewRetryableError(fmt.Errorf("failed to patch the finalizers of eventsource %s/%s: %w", es.Namespace, es.Name, err))
+	}
+
+	return nil
+}
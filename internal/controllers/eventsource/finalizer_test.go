@@ -0,0 +1,127 @@
+package eventsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	esfake "github.com/argoproj/argo-events/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+)
+
+type FakeFailingConfigurator struct {
+	FakeConfigurator
+	err error
+}
+
+func (c *FakeFailingConfigurator) Remove(ctx context.Context, config *v1.EventSourceIngressConfig) error {
+	_ = c.FakeConfigurator.Remove(ctx, config)
+	return c.err
+}
+
+func TestReconcileFinalizer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	deleted := func(es *esv1alpha1.EventSource, at time.Time) *esv1alpha1.EventSource {
+		es.DeletionTimestamp = &metav1.Time{Time: at}
+		return es
+	}
+	finalized := func(es *esv1alpha1.EventSource) *esv1alpha1.EventSource {
+		es.Finalizers = []string{"other", DefaultFinalizer}
+		return es
+	}
+
+	tests := []struct {
+		name           string
+		es             *esv1alpha1.EventSource
+		removeErr      error
+		wantFinalizers []string
+		wantErr        bool
+		wantEvent      bool
+	}{
+		{
+			name:           "managed",
+			es:             newManagedEventSource("es", "github"),
+			wantFinalizers: []string{DefaultFinalizer},
+		},
+		{
+			name:           "opted out",
+			es:             finalized(newManagedEventSource("es", "")),
+			wantFinalizers: []string{"other"},
+		},
+		{
+			name:           "deleted",
+			es:             deleted(finalized(newManagedEventSource("es", "github")), now),
+			wantFinalizers: []string{"other"},
+		},
+		{
+			name:           "deleted with retryable removal error",
+			es:             deleted(finalized(newManagedEventSource("es", "github")), now),
+			removeErr:      perrs.NewRetryableError(errors.New("api unavailable")),
+			wantFinalizers: []string{"other", DefaultFinalizer},
+			wantErr:        true,
+		},
+		{
+			name:           "deleted with permanent removal error",
+			es:             deleted(finalized(newManagedEventSource("es", "github")), now),
+			removeErr:      perrs.NewUnretryableError(errors.New("invalid config")),
+			wantFinalizers: []string{"other"},
+			wantEvent:      true,
+		},
+		{
+			name:           "stuck in deletion",
+			es:             deleted(finalized(newManagedEventSource("es", "github")), now.Add(-time.Hour)),
+			removeErr:      perrs.NewRetryableError(errors.New("api unavailable")),
+			wantFinalizers: []string{"other"},
+			wantEvent:      true,
+		},
+	}
+
+	for _, test := range tests {
+		svcl := &FakeServiceNamespaceLister{}
+		svcl.AppendService(&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "es-eventsource-svc", Namespace: "ns"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 12000, TargetPort: intstr.FromInt32(12000)}},
+			},
+		})
+		sl := &FakeServiceLister{}
+		sl.SetNamespaceLister(svcl)
+
+		config := NewEventSourceIngressControllerConfig()
+		config.SetIPGetter("github", &FakeIPGetter{})
+
+		client := esfake.NewSimpleClientset(test.es.DeepCopy())
+		recorder := record.NewFakeRecorder(10)
+
+		controller := NewEventSourceIngressController(&FakeESLister{}, sl, config, &FakeFailingConfigurator{err: test.removeErr})
+		controller.SetEventSourceClient(client.ArgoprojV1alpha1())
+		controller.SetEventRecorder(recorder)
+		controller.SetStuckFinalizerTimeout(30 * time.Minute)
+
+		err := controller.reconcile(context.TODO(), test.es, types.NamespacedName{Namespace: "ns", Name: "es"}, &IngressStatus{})
+		assert.Equal(t, test.wantErr, err != nil, test.name)
+
+		es, err := client.ArgoprojV1alpha1().EventSources("ns").Get(context.TODO(), "es", metav1.GetOptions{})
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.wantFinalizers, es.Finalizers, test.name)
+
+		if test.wantEvent {
+			assert.Contains(t, <-recorder.Events, ReasonFinalizerReleased, test.name)
+		} else {
+			assert.Empty(t, recorder.Events, test.name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package eventsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// CollectGarbage removes generated ingress resources whose EventSource no longer exists or no longer
+// qualifies for managed ingress. It is a one time sweep meant to run at startup, catching deletions
+// and opt outs that happened while the controller was not running.
+func (e *EventSourceIngressController) CollectGarbage(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	lister, ok := e.igc.(v1.IngressLister)
+	if !ok {
+		return nil
+	}
+
+	config := v1.EventSourceIngressConfig{
+		Gateway:        e.config.Gateway,
+		AdminNamespace: e.config.AdminNamespace,
+		BaseURL:        e.config.BaseURL,
+	}
+
+	eventSources, err := lister.ListEventSources(ctx, &config)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, nsn := range eventSources {
+		inScope, err := e.inScope(nsn.Namespace)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if !inScope {
+			continue
+		}
+
+		es, err := e.esLister.EventSources(nsn.Namespace).Get(nsn.Name)
+		if err != nil && !k8serror.IsNotFound(err) {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		if err == nil && e.qualifies(es) {
+			continue
+		}
+
+		log.Info(fmt.Sprintf("Removing ingress of eventsource %s", nsn.String()))
+		config.Eventsource = nsn
+		if err := e.igc.Remove(ctx, &config); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// qualifies reports whether an EventSource opted into managed ingress with at least one webhook
+// assigned to a supported known source.
+func (e *EventSourceIngressController) qualifies(es *esv1alpha1.EventSource) bool {
+	for _, source := range e.hookSources(es) {
+		if _, ok := e.config.ipGetters[source]; ok {
+			return true
+		}
+	}
+	return false
+}
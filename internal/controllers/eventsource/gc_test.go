@@ -0,0 +1,123 @@
+package eventsource
+
+import (
+	"context"
+	"testing"
+
+	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+)
+
+type FakeListingConfigurator struct {
+	FakeConfigurator
+	eventSources []types.NamespacedName
+	removedNames []types.NamespacedName
+}
+
+func (c *FakeListingConfigurator) Remove(ctx context.Context, config *v1.EventSourceIngressConfig) error {
+	c.removedNames = append(c.removedNames, config.Eventsource)
+	return c.FakeConfigurator.Remove(ctx, config)
+}
+
+func (c *FakeListingConfigurator) ListEventSources(ctx context.Context, config *v1.EventSourceIngressConfig) ([]types.NamespacedName, error) {
+	return c.eventSources, nil
+}
+
+func newManagedEventSource(name, source string) *esv1alpha1.EventSource {
+	es := &esv1alpha1.EventSource{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+		Spec: esv1alpha1.EventSourceSpec{
+			Webhook: map[string]esv1alpha1.WebhookEventSource{
+				"hook": {WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/hook", Port: "12000", AuthSecret: &corev1.SecretKeySelector{}}},
+			},
+		},
+	}
+	if source != "" {
+		es.Annotations = map[string]string{eshandler.DefaultAnnotationKey: source}
+	}
+	return es
+}
+
+func TestCollectGarbage(t *testing.T) {
+	t.Parallel()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, es := range []*esv1alpha1.EventSource{
+		newManagedEventSource("managed", "github"),
+		newManagedEventSource("opted-out", ""),
+		newManagedEventSource("unsupported", "jira"),
+	} {
+		require.NoError(t, indexer.Add(es))
+	}
+
+	config := NewEventSourceIngressControllerConfig()
+	config.SetIPGetter("github", &FakeIPGetter{})
+
+	igc := &FakeListingConfigurator{
+		eventSources: []types.NamespacedName{
+			{Namespace: "ns", Name: "managed"},
+			{Namespace: "ns", Name: "opted-out"},
+			{Namespace: "ns", Name: "unsupported"},
+			{Namespace: "ns", Name: "deleted"},
+		},
+	}
+
+	controller := NewEventSourceIngressController(eslister.NewEventSourceLister(indexer), &FakeServiceLister{}, config, igc)
+	require.NoError(t, controller.CollectGarbage(context.TODO()))
+
+	assert.Equal(t, []types.NamespacedName{
+		{Namespace: "ns", Name: "opted-out"},
+		{Namespace: "ns", Name: "unsupported"},
+		{Namespace: "ns", Name: "deleted"},
+	}, igc.removedNames)
+
+	// configurators without listing support are skipped
+	controller = NewEventSourceIngressController(eslister.NewEventSourceLister(indexer), &FakeServiceLister{}, config, &FakeConfigurator{})
+	assert.NoError(t, controller.CollectGarbage(context.TODO()))
+}
+
+func TestReconcileRemovesUnqualifiedIngress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		es      *esv1alpha1.EventSource
+		wantErr bool
+	}{
+		{name: "known source removed", es: newManagedEventSource("es", "")},
+		{name: "hook type unsupported", es: newManagedEventSource("es", "jira"), wantErr: true},
+	}
+
+	for _, test := range tests {
+		svcl := &FakeServiceNamespaceLister{}
+		svcl.AppendService(&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "es-eventsource-svc", Namespace: "ns"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 12000, TargetPort: intstr.FromInt32(12000)}},
+			},
+		})
+		sl := &FakeServiceLister{}
+		sl.SetNamespaceLister(svcl)
+
+		config := NewEventSourceIngressControllerConfig()
+		config.SetIPGetter("github", &FakeIPGetter{})
+
+		igc := &FakeConfigurator{}
+		controller := NewEventSourceIngressController(&FakeESLister{}, sl, config, igc)
+
+		err := controller.reconcile(context.TODO(), test.es, types.NamespacedName{Namespace: "ns", Name: "es"}, &IngressStatus{})
+		assert.Equal(t, test.wantErr, err != nil, test.name)
+		assert.Equal(t, 1, igc.removed, test.name)
+		assert.Equal(t, 0, igc.configured, test.name)
+	}
+}
@@ -0,0 +1,63 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// WatchIPGetters sends an event for every EventSource using a known source whenever the CIDRs of the
+// source change, for IPGetters implementing v1.IPWatcher. The events are meant for a channel source
+// of the controller so the AuthorizationPolicies pick up the new CIDRs. Sends block until the events are
+// received, it must only run where the channel source is started, i.e. on the leader.
+func (e *EventSourceIngressController) WatchIPGetters(ctx context.Context, events chan<- event.GenericEvent) {
+	for source, getter := range e.config.ipGetters {
+		watcher, ok := getter.(v1.IPWatcher)
+		if !ok {
+			continue
+		}
+
+		watcher.Watch(ctx, func() {
+			// notifications are delivered synchronously by the syncing caller, which may be a reconcile
+			go e.enqueueSource(ctx, source, events)
+		})
+	}
+}
+
+// enqueueSource sends an event for every EventSource with a webhook assigned to the known source.
+func (e *EventSourceIngressController) enqueueSource(ctx context.Context, source string, events chan<- event.GenericEvent) {
+	log := log.FromContext(ctx)
+
+	eventSources, err := e.esLister.List(labels.Everything())
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to list eventsources for the changed CIDRs of %s", source))
+		return
+	}
+
+	count := 0
+	for _, es := range eventSources {
+		sources := e.hookSources(es)
+		if !slices.Contains(slices.Collect(maps.Values(sources)), source) {
+			continue
+		}
+		if inScope, err := e.inScope(es.Namespace); err != nil || !inScope {
+			continue
+		}
+
+		select {
+		case events <- event.GenericEvent{Object: es}:
+			count++
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	log.Info(fmt.Sprintf("CIDRs of %s changed, enqueued %d eventsources", source, count))
+}
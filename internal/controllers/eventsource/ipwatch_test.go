@@ -0,0 +1,81 @@
+package eventsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+type FakeIPWatcher struct {
+	FakeIPGetter
+	notify func()
+}
+
+func (f *FakeIPWatcher) Watch(ctx context.Context, notify func()) {
+	f.notify = notify
+}
+
+func TestWatchIPGetters(t *testing.T) {
+	t.Parallel()
+
+	newEventSource := func(name string, annotations map[string]string) *esv1alpha1.EventSource {
+		return &esv1alpha1.EventSource{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Annotations: annotations},
+			Spec: esv1alpha1.EventSourceSpec{
+				Webhook: map[string]esv1alpha1.WebhookEventSource{
+					"hook": {WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/hook", Port: "12000", AuthSecret: &corev1.SecretKeySelector{}}},
+				},
+			},
+		}
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, es := range []*esv1alpha1.EventSource{
+		newEventSource("github", map[string]string{eshandler.DefaultAnnotationKey: "github"}),
+		newEventSource("mapped", map[string]string{eshandler.DefaultMappingAnnotationKey: "hook=github"}),
+		newEventSource("jira", map[string]string{eshandler.DefaultAnnotationKey: "jira"}),
+		newEventSource("unmanaged", nil),
+	} {
+		require.NoError(t, indexer.Add(es))
+	}
+
+	github := &FakeIPWatcher{}
+	config := NewEventSourceIngressControllerConfig()
+	config.SetIPGetter("github", github)
+	config.SetIPGetter("jira", &FakeIPGetter{})
+
+	controller := NewEventSourceIngressController(eslister.NewEventSourceLister(indexer), &FakeServiceLister{}, config, &FakeConfigurator{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan event.GenericEvent, 10)
+	controller.WatchIPGetters(ctx, events)
+	require.NotNil(t, github.notify)
+
+	github.notify()
+
+	names := []string{}
+	for len(names) < 2 {
+		select {
+		case e := <-events:
+			names = append(names, e.Object.GetName())
+		case <-time.After(5 * time.Second):
+			t.Fatal("missing eventsource events")
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"github", "mapped"}, names)
+	assert.Never(t, func() bool { return len(events) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+}
@@ -0,0 +1,53 @@
+package eventsource
+
+import (
+	"slices"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// NamespacedEventSourceLister combines the listers of EventSource informers restricted to a single
+// namespace each, keyed by namespace. EventSources of other namespaces are not found.
+type NamespacedEventSourceLister map[string]eslister.EventSourceLister
+
+// List lists the EventSources of every namespace ordered by namespace.
+func (l NamespacedEventSourceLister) List(selector labels.Selector) ([]*esv1alpha1.EventSource, error) {
+	namespaces := make([]string, 0, len(l))
+	for namespace := range l {
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+
+	out := []*esv1alpha1.EventSource{}
+	for _, namespace := range namespaces {
+		eventSources, err := l[namespace].EventSources(namespace).List(selector)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, eventSources...)
+	}
+
+	return out, nil
+}
+
+// EventSources returns the lister of a namespace.
+func (l NamespacedEventSourceLister) EventSources(namespace string) eslister.EventSourceNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.EventSources(namespace)
+	}
+	return emptyNamespaceLister{}
+}
+
+// emptyNamespaceLister lists the EventSources of namespaces without an informer.
+type emptyNamespaceLister struct{}
+
+func (emptyNamespaceLister) List(selector labels.Selector) ([]*esv1alpha1.EventSource, error) {
+	return []*esv1alpha1.EventSource{}, nil
+}
+
+func (emptyNamespaceLister) Get(name string) (*esv1alpha1.EventSource, error) {
+	return nil, k8serror.NewNotFound(esv1alpha1.Resource("eventsource"), name)
+}
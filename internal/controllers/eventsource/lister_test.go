@@ -0,0 +1,45 @@
+package eventsource
+
+import (
+	"testing"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNamespacedEventSourceLister(t *testing.T) {
+	t.Parallel()
+
+	lister := NamespacedEventSourceLister{}
+	for _, namespace := range []string{"b", "a"} {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		es := newManagedEventSource("es", "github")
+		es.Namespace = namespace
+		require.NoError(t, indexer.Add(es))
+		lister[namespace] = eslister.NewEventSourceLister(indexer)
+	}
+
+	eventSources, err := lister.List(labels.Everything())
+	require.NoError(t, err)
+	namespaces := []string{}
+	for _, es := range eventSources {
+		namespaces = append(namespaces, es.Namespace)
+	}
+	assert.Equal(t, []string{"a", "b"}, namespaces)
+
+	es, err := lister.EventSources("b").Get("es")
+	require.NoError(t, err)
+	assert.Equal(t, "b", es.Namespace)
+
+	_, err = lister.EventSources("c").Get("es")
+	assert.True(t, k8serror.IsNotFound(err))
+
+	eventSources, err = lister.EventSources("c").List(labels.Everything())
+	assert.NoError(t, err)
+	assert.Equal(t, []*esv1alpha1.EventSource{}, eventSources)
+}
@@ -0,0 +1,80 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+
+	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+	"github.com/kanopy-platform/argoslower/pkg/namespace"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Status reasons of the mesh enrollment check of EventSource namespaces.
+const (
+	ReasonNamespaceOffMesh string = "NamespaceOffMesh"
+	ReasonMeshLookupFailed string = "MeshLookupFailed"
+)
+
+// SetMeshChecker enables removing the ingress of EventSources in namespaces that are not enrolled in
+// the mesh. Mesh enrollment is otherwise only checked at admission.
+func (e *EventSourceIngressController) SetMeshChecker(mc eshandler.MeshChecker) {
+	e.meshChecker = mc
+}
+
+// offMesh reports whether the namespace of an EventSource is not enrolled in the mesh.
+func (e *EventSourceIngressController) offMesh(ns string) (bool, error) {
+	if e.meshChecker == nil {
+		return false, nil
+	}
+
+	info, err := e.meshChecker.MeshMode(ns)
+	if err != nil {
+		return false, perrs.NewRetryableError(fmt.Errorf("unable to look up the mesh enrollment of namespace %s: %w", ns, err))
+	}
+
+	return info.Mode == namespace.DataplaneNone, nil
+}
+
+// NamespaceRequests maps a Namespace to requests reconciling its EventSources with managed ingress, or
+// its EventSources holding the finalizer when the Namespace is out of scope.
+func (e *EventSourceIngressController) NamespaceRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := log.FromContext(ctx)
+
+	inScope, err := e.inScope(obj.GetName())
+	if err != nil {
+		return nil
+	}
+
+	eventSources, err := e.esLister.EventSources(obj.GetName()).List(labels.Everything())
+	if err != nil {
+		log.Error(err, fmt.Sprintf("unable to list eventsources of namespace %s", obj.GetName()))
+		return nil
+	}
+
+	out := []reconcile.Request{}
+	for _, es := range eventSources {
+		// EventSources of namespaces leaving the scope are enqueued for releasing the finalizer
+		if !inScope && !controllerutil.ContainsFinalizer(es, e.finalizer) {
+			continue
+		}
+		if inScope && !eshandler.HasKnownSource(es, e.annotationKey, e.mappingAnnotationKey) {
+			continue
+		}
+		out = append(out, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: es.Namespace, Name: es.Name}})
+	}
+
+	return out
+}
+
+// EnqueueForNamespace enqueues the EventSources with managed ingress of a Namespace.
+func (e *EventSourceIngressController) EnqueueForNamespace() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(e.NamespaceRequests)
+}
@@ -0,0 +1,116 @@
+package eventsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	estest "github.com/kanopy-platform/argoslower/internal/admission/eventsource/testing"
+	"github.com/kanopy-platform/argoslower/pkg/namespace"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileMesh(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		checker     *estest.FakeMeshChecker
+		wantRemoved bool
+		wantReason  string
+		wantErr     bool
+	}{
+		{name: "no mesh checker", wantReason: ReasonConfigured},
+		{name: "sidecar", checker: &estest.FakeMeshChecker{Mesh: true}, wantReason: ReasonConfigured},
+		{name: "ambient", checker: &estest.FakeMeshChecker{Mesh: true, Info: namespace.MeshInfo{Mode: namespace.DataplaneAmbient}}, wantReason: ReasonConfigured},
+		{name: "off mesh", checker: &estest.FakeMeshChecker{}, wantRemoved: true, wantReason: ReasonNamespaceOffMesh},
+		{name: "lookup error", checker: &estest.FakeMeshChecker{Err: errors.New("test error")}, wantReason: ReasonMeshLookupFailed, wantErr: true},
+	}
+
+	for _, test := range tests {
+		es := newManagedEventSource("es", "github")
+
+		svcl := &FakeServiceNamespaceLister{}
+		svcl.AppendService(&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "es-eventsource-svc", Namespace: es.Namespace},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Port: 12000, TargetPort: intstr.FromInt32(12000)}},
+			},
+		})
+		sl := &FakeServiceLister{}
+		sl.SetNamespaceLister(svcl)
+
+		config := NewEventSourceIngressControllerConfig()
+		config.Gateway = types.NamespacedName{Name: "gateway", Namespace: "routing"}
+		config.AdminNamespace = "routing"
+		config.BaseURL = "webhooks.example.com"
+		config.SetIPGetter("github", &FakeIPGetter{})
+
+		igc := &FakeConfigurator{}
+		controller := NewEventSourceIngressController(&FakeESLister{}, sl, config, igc)
+		if test.checker != nil {
+			controller.SetMeshChecker(test.checker)
+		}
+
+		status := &IngressStatus{}
+		err := controller.reconcile(context.TODO(), es, types.NamespacedName{Namespace: es.Namespace, Name: es.Name}, status)
+		assert.Equal(t, test.wantErr, err != nil, test.name)
+		assert.Equal(t, test.wantReason, status.Reason, test.name)
+
+		switch {
+		case test.wantRemoved:
+			assert.Equal(t, 1, igc.removed, test.name)
+			assert.Equal(t, 0, igc.configured, test.name)
+			assert.False(t, status.Ready, test.name)
+			assert.NotEmpty(t, status.LastError, test.name)
+		case !test.wantErr:
+			assert.Equal(t, 0, igc.removed, test.name)
+			assert.Equal(t, 1, igc.configured, test.name)
+			assert.True(t, status.Ready, test.name)
+		}
+	}
+}
+
+func TestNamespaceRequests(t *testing.T) {
+	t.Parallel()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, es := range []*esv1alpha1.EventSource{
+		newManagedEventSource("managed", "github"),
+		newManagedEventSource("unmanaged", ""),
+	} {
+		require.NoError(t, indexer.Add(es))
+	}
+
+	other := newManagedEventSource("other", "github")
+	other.Namespace = "other"
+	require.NoError(t, indexer.Add(other))
+
+	controller := NewEventSourceIngressController(eslister.NewEventSourceLister(indexer), &FakeServiceLister{}, NewEventSourceIngressControllerConfig(), &FakeConfigurator{})
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "managed"}},
+	}, controller.NamespaceRequests(context.TODO(), ns))
+
+	controller.SetScope(fakeScope{"other": true})
+	assert.Empty(t, controller.NamespaceRequests(context.TODO(), ns))
+
+	// EventSources holding the finalizer are enqueued for releasing it when the namespace leaves the scope
+	finalized := newManagedEventSource("finalized", "")
+	finalized.Finalizers = []string{DefaultFinalizer}
+	require.NoError(t, indexer.Add(finalized))
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "finalized"}},
+	}, controller.NamespaceRequests(context.TODO(), ns))
+}
@@ -0,0 +1,43 @@
+package eventsource
+
+import (
+	"time"
+
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// serviceRetryAfter retries EventSources whose Service is not created yet.
+	serviceRetryAfter = 5 * time.Second
+	// ipRetryAfter retries EventSources whose known source CIDRs could not be sourced.
+	ipRetryAfter = time.Minute
+)
+
+// reasonUnknown labels reconciliation errors without a reason code.
+const reasonUnknown string = "Unknown"
+
+var reconcileErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "argoslower_eventsource_reconcile_errors_total",
+		Help: "Total number of failed EventSource ingress reconciliations by reason",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileErrors)
+}
+
+// errorReason returns the reason code of a failed reconciliation.
+func errorReason(status *IngressStatus, err error) string {
+	if reason := perrs.ReasonOf(err); reason != "" {
+		return reason
+	}
+	if status != nil && status.Reason != "" {
+		return status.Reason
+	}
+	return reasonUnknown
+}
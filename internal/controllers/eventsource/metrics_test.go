@@ -0,0 +1,61 @@
+package eventsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	esl := &FakeESNamespaceLister{}
+	esl.AppendES(newManagedEventSource("es", "github"))
+	fesl := &FakeESLister{}
+	fesl.SetNamespaceLister(esl)
+
+	sl := &FakeServiceLister{}
+	sl.SetNamespaceLister(&FakeServiceNamespaceLister{})
+
+	config := NewEventSourceIngressControllerConfig()
+	config.SetIPGetter("github", &FakeIPGetter{})
+
+	controller := NewEventSourceIngressController(fesl, sl, config, &FakeConfigurator{})
+
+	before := reconcileErrorCount(t, ReasonServiceNotFound)
+
+	result, err := controller.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "es"}})
+	assert.NoError(t, err)
+	assert.Equal(t, serviceRetryAfter, result.RequeueAfter)
+	assert.Equal(t, before+1, reconcileErrorCount(t, ReasonServiceNotFound))
+}
+
+func reconcileErrorCount(t *testing.T, reason string) float64 {
+	t.Helper()
+
+	m := &dto.Metric{}
+	require.NoError(t, reconcileErrors.WithLabelValues(reason).Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func TestErrorReason(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New("test error")
+
+	assert.Equal(t, reasonUnknown, errorReason(nil, err))
+	assert.Equal(t, ReasonConfigureFailed, errorReason(&IngressStatus{Reason: ReasonConfigureFailed}, err))
+	assert.Equal(t, "IPProviderThrottled", errorReason(&IngressStatus{Reason: ReasonConfigureFailed}, perrs.NewRetryableError(err, perrs.WithReason("IPProviderThrottled"))))
+
+	status := &IngressStatus{}
+	_ = status.fail(ReasonIPFetchFailed, perrs.NewRetryableError(err, perrs.WithReason("IPProviderThrottled")))
+	assert.Equal(t, "IPProviderThrottled", status.Reason)
+}
@@ -0,0 +1,46 @@
+package eventsource
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// RateLimiterOptions configures the per EventSource backoff of failed reconciliations and the overall
+// rate EventSources are taken from the reconcile queue at.
+type RateLimiterOptions struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	QPS       float64
+	Burst     int
+}
+
+// DefaultRateLimiterOptions returns the controller-runtime defaults.
+func DefaultRateLimiterOptions() RateLimiterOptions {
+	return RateLimiterOptions{
+		BaseDelay: 5 * time.Millisecond,
+		MaxDelay:  1000 * time.Second,
+		QPS:       10,
+		Burst:     100,
+	}
+}
+
+// NewRateLimiter returns a rate limiter delaying EventSources by the larger of their exponential
+// backoff and the overall token bucket. It only applies to rate limited requeues of failed
+// reconciliations, EventSources added by watch events and resyncs are not delayed.
+func NewRateLimiter(o RateLimiterOptions) (workqueue.TypedRateLimiter[reconcile.Request], error) {
+	if o.BaseDelay <= 0 || o.MaxDelay < o.BaseDelay {
+		return nil, fmt.Errorf("invalid reconcile backoff: base delay %s must be positive and not exceed the max delay %s", o.BaseDelay, o.MaxDelay)
+	}
+	if o.QPS <= 0 || o.Burst <= 0 {
+		return nil, fmt.Errorf("invalid reconcile rate: qps %v and burst %d must be positive", o.QPS, o.Burst)
+	}
+
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](o.BaseDelay, o.MaxDelay),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(o.QPS), o.Burst)},
+	), nil
+}
@@ -0,0 +1,71 @@
+package eventsource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options RateLimiterOptions
+		delays  []time.Duration
+		err     bool
+	}{
+		{
+			name:    "defaults",
+			options: DefaultRateLimiterOptions(),
+			delays:  []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond},
+		},
+		{
+			name:    "capped backoff",
+			options: RateLimiterOptions{BaseDelay: time.Second, MaxDelay: 3 * time.Second, QPS: 10, Burst: 100},
+			delays:  []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			name:    "zero base delay",
+			options: RateLimiterOptions{MaxDelay: time.Second, QPS: 10, Burst: 100},
+			err:     true,
+		},
+		{
+			name:    "max delay below base delay",
+			options: RateLimiterOptions{BaseDelay: time.Second, MaxDelay: time.Millisecond, QPS: 10, Burst: 100},
+			err:     true,
+		},
+		{
+			name:    "zero qps",
+			options: RateLimiterOptions{BaseDelay: time.Millisecond, MaxDelay: time.Second, Burst: 100},
+			err:     true,
+		},
+		{
+			name:    "zero burst",
+			options: RateLimiterOptions{BaseDelay: time.Millisecond, MaxDelay: time.Second, QPS: 10},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		limiter, err := NewRateLimiter(test.options)
+		if test.err {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "es"}}
+		for _, delay := range test.delays {
+			assert.Equal(t, delay, limiter.When(req), test.name)
+		}
+		assert.Equal(t, len(test.delays), limiter.NumRequeues(req), test.name)
+
+		limiter.Forget(req)
+		assert.Equal(t, test.options.BaseDelay, limiter.When(req), test.name)
+	}
+}
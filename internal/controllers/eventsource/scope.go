@@ -0,0 +1,94 @@
+package eventsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ScopeChecker reports whether the EventSources of a namespace are managed by this instance.
+type ScopeChecker interface {
+	InScope(namespace string) (bool, error)
+}
+
+// SetScope restricts the controller to EventSources of namespaces in scope. The ingress of
+// EventSources out of scope is neither configured nor removed, only the finalizer of this instance
+// is released so they can still be deleted.
+func (e *EventSourceIngressController) SetScope(s ScopeChecker) {
+	e.scope = s
+}
+
+// inScope reports whether an EventSource namespace is in scope, every namespace is without a scope.
+func (e *EventSourceIngressController) inScope(namespace string) (bool, error) {
+	if e.scope == nil {
+		return true, nil
+	}
+	return e.scope.InScope(namespace)
+}
+
+// releaseOutOfScope releases the finalizer of an EventSource that left the scope. The ingress of a
+// deleted EventSource is removed before, the ingress of others is left in place.
+func (e *EventSourceIngressController) releaseOutOfScope(ctx context.Context, es *esv1alpha1.EventSource) error {
+	if !controllerutil.ContainsFinalizer(es, e.finalizer) {
+		return nil
+	}
+
+	if es.DeletionTimestamp != nil {
+		return e.finalize(ctx, es, &v1.EventSourceIngressConfig{
+			Eventsource:    types.NamespacedName{Namespace: es.Namespace, Name: es.Name},
+			Gateway:        e.config.Gateway,
+			AdminNamespace: e.config.AdminNamespace,
+			BaseURL:        e.config.BaseURL,
+		})
+	}
+
+	return e.releaseFinalizer(ctx, es)
+}
+
+// ReleaseOutOfScope releases the finalizer of EventSources in namespaces out of scope. Namespaces
+// dropped from the watched namespaces are not seen by the informers, this is a one time sweep meant
+// to run at startup listing EventSources of every namespace through the EventSource client.
+func (e *EventSourceIngressController) ReleaseOutOfScope(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	if e.scope == nil || e.esClient == nil {
+		return nil
+	}
+
+	list, err := e.esClient.EventSources(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for i := range list.Items {
+		es := &list.Items[i]
+		if !controllerutil.ContainsFinalizer(es, e.finalizer) {
+			continue
+		}
+
+		inScope, err := e.inScope(es.Namespace)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if inScope {
+			continue
+		}
+
+		log.Info(fmt.Sprintf("Releasing finalizer %s of eventsource %s/%s out of scope", e.finalizer, es.Namespace, es.Name))
+		if err := e.releaseOutOfScope(ctx, es); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	return errs
+}
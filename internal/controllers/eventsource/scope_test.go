@@ -0,0 +1,180 @@
+package eventsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	esfake "github.com/argoproj/argo-events/pkg/client/clientset/versioned/fake"
+	eslister "github.com/argoproj/argo-events/pkg/client/listers/events/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type fakeScope map[string]bool
+
+func (f fakeScope) InScope(namespace string) (bool, error) {
+	return f[namespace], nil
+}
+
+func TestReconcileScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		scope         ScopeChecker
+		annotationKey string
+		source        string
+		removed       int
+	}{
+		{
+			name:    "no scope",
+			removed: 1,
+		},
+		{
+			name:    "in scope",
+			scope:   fakeScope{"ns": true},
+			removed: 1,
+		},
+		{
+			name:  "out of scope",
+			scope: fakeScope{"other": true},
+		},
+		{
+			name:          "annotation key of another instance",
+			scope:         fakeScope{"ns": true},
+			annotationKey: "tenant.example.com/known-source",
+			source:        "github",
+			removed:       1,
+		},
+	}
+
+	for _, test := range tests {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		require.NoError(t, indexer.Add(newManagedEventSource("es", test.source)), test.name)
+
+		config := NewEventSourceIngressControllerConfig()
+		config.SetIPGetter("github", &FakeIPGetter{})
+
+		igc := &FakeListingConfigurator{}
+		controller := NewEventSourceIngressController(eslister.NewEventSourceLister(indexer), &FakeServiceLister{}, config, igc)
+		controller.SetScope(test.scope)
+		controller.SetAnnotationKey(test.annotationKey)
+
+		// EventSources without a known source of this instance are torn down
+		_, err := controller.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "es"}})
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.removed, igc.removed, test.name)
+		assert.Equal(t, 0, igc.configured, test.name)
+	}
+}
+
+func TestCollectGarbageScope(t *testing.T) {
+	t.Parallel()
+
+	config := NewEventSourceIngressControllerConfig()
+	config.SetIPGetter("github", &FakeIPGetter{})
+
+	igc := &FakeListingConfigurator{
+		eventSources: []types.NamespacedName{
+			{Namespace: "ns", Name: "deleted"},
+			{Namespace: "other", Name: "deleted"},
+		},
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	controller := NewEventSourceIngressController(eslister.NewEventSourceLister(indexer), &FakeServiceLister{}, config, igc)
+	controller.SetScope(fakeScope{"ns": true})
+	require.NoError(t, controller.CollectGarbage(context.TODO()))
+
+	assert.Equal(t, []types.NamespacedName{{Namespace: "ns", Name: "deleted"}}, igc.removedNames)
+}
+
+func TestReconcileScopeFinalizer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		deleted bool
+		removed int
+	}{
+		{name: "left the scope"},
+		{name: "deleted out of scope", deleted: true, removed: 1},
+	}
+
+	for _, test := range tests {
+		es := newManagedEventSource("es", "github")
+		es.Finalizers = []string{"other.example.com/finalizer", DefaultFinalizer}
+		if test.deleted {
+			es.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		require.NoError(t, indexer.Add(es), test.name)
+		client := esfake.NewSimpleClientset(es)
+
+		igc := &FakeListingConfigurator{}
+		controller := NewEventSourceIngressController(eslister.NewEventSourceLister(indexer), &FakeServiceLister{}, NewEventSourceIngressControllerConfig(), igc)
+		controller.SetEventSourceClient(client.ArgoprojV1alpha1())
+		controller.SetScope(fakeScope{"other": true})
+
+		// the finalizer of this instance is released so EventSources out of scope can be deleted
+		_, err := controller.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "es"}})
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.removed, igc.removed, test.name)
+		assert.Equal(t, 0, igc.configured, test.name)
+
+		patched, err := client.ArgoprojV1alpha1().EventSources("ns").Get(context.TODO(), "es", metav1.GetOptions{})
+		require.NoError(t, err, test.name)
+		assert.Equal(t, []string{"other.example.com/finalizer"}, patched.Finalizers, test.name)
+	}
+}
+
+func TestReleaseOutOfScope(t *testing.T) {
+	t.Parallel()
+
+	inScope := newManagedEventSource("in-scope", "github")
+	inScope.Finalizers = []string{DefaultFinalizer}
+	outOfScope := newManagedEventSource("out-of-scope", "github")
+	outOfScope.Namespace = "other"
+	outOfScope.Finalizers = []string{DefaultFinalizer}
+	client := esfake.NewSimpleClientset(inScope, outOfScope)
+
+	igc := &FakeListingConfigurator{}
+	controller := NewEventSourceIngressController(&FakeESLister{}, &FakeServiceLister{}, NewEventSourceIngressControllerConfig(), igc)
+	controller.SetEventSourceClient(client.ArgoprojV1alpha1())
+	controller.SetScope(fakeScope{"ns": true})
+	require.NoError(t, controller.ReleaseOutOfScope(context.TODO()))
+
+	es, err := client.ArgoprojV1alpha1().EventSources("ns").Get(context.TODO(), "in-scope", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultFinalizer}, es.Finalizers)
+
+	es, err = client.ArgoprojV1alpha1().EventSources("other").Get(context.TODO(), "out-of-scope", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, es.Finalizers)
+	assert.Equal(t, 0, igc.removed)
+}
+
+func TestHookSourcesAnnotationKeys(t *testing.T) {
+	t.Parallel()
+
+	es := newManagedEventSource("es", "")
+	es.Annotations = map[string]string{
+		"tenant.example.com/known-source":         "github",
+		"tenant.example.com/known-source-mapping": "hook=jira",
+	}
+
+	controller := NewEventSourceIngressController(&FakeESLister{}, &FakeServiceLister{}, NewEventSourceIngressControllerConfig(), &FakeConfigurator{})
+	assert.Empty(t, controller.hookSources(es))
+
+	controller.SetAnnotationKey("tenant.example.com/known-source")
+	assert.Equal(t, map[string]string{"hook": "github"}, controller.hookSources(es))
+
+	controller.SetMappingAnnotationKey("tenant.example.com/known-source-mapping")
+	assert.Equal(t, map[string]string{"hook": "jira"}, controller.hookSources(es))
+}
@@ -0,0 +1,111 @@
+package eventsource
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+	ingresscommon "github.com/kanopy-platform/argoslower/pkg/ingress"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// SetSecretClient configures the client used for creating the generated webhook secrets of
+// EventSources. Secrets are not generated without a client.
+func (e *EventSourceIngressController) SetSecretClient(c corev1client.SecretsGetter) {
+	e.secretClient = c
+}
+
+// ensureGeneratedSecret creates the generated Secret wired into the EventSource at admission and adds
+// missing keys to it. Existing keys are never rotated. The Secret is owned by the EventSource and
+// garbage collected with it.
+func (e *EventSourceIngressController) ensureGeneratedSecret(ctx context.Context, es *esv1alpha1.EventSource) error {
+	if e.secretClient == nil {
+		return nil
+	}
+
+	name, ok := es.Annotations[eshandler.DefaultGeneratedSecretAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	keys := eshandler.GeneratedSecretKeys(es)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	secrets := e.secretClient.Secrets(es.Namespace)
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if k8serror.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: es.Namespace,
+				Labels: map[string]string{
+					ingresscommon.EventSourceNameString:      es.Name,
+					ingresscommon.EventSourceNamespaceString: es.Namespace,
+				},
+				OwnerReferences: []metav1.OwnerReference{eventSourceOwnerReference(es)},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{},
+		}
+
+		for _, key := range keys {
+			secret.Data[key] = []byte(rand.Text())
+		}
+
+		if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return perrs.NewRetryableError(fmt.Errorf("failed to create generated secret %s/%s: %w", es.Namespace, name, err))
+		}
+		return nil
+	}
+
+	if err != nil {
+		return perrs.NewRetryableError(err)
+	}
+
+	if !metav1.IsControlledBy(secret, es) {
+		return perrs.NewUnretryableError(fmt.Errorf("secret %s/%s exists and is not owned by eventsource %s", es.Namespace, name, es.Name))
+	}
+
+	updated := secret.DeepCopy()
+	if updated.Data == nil {
+		updated.Data = map[string][]byte{}
+	}
+
+	missing := false
+	for _, key := range keys {
+		if _, ok := updated.Data[key]; !ok {
+			updated.Data[key] = []byte(rand.Text())
+			missing = true
+		}
+	}
+
+	if !missing {
+		return nil
+	}
+
+	if _, err := secrets.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return perrs.NewRetryableError(fmt.Errorf("failed to update generated secret %s/%s: %w", es.Namespace, name, err))
+	}
+
+	return nil
+}
+
+func eventSourceOwnerReference(es *esv1alpha1.EventSource) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: esv1alpha1.SchemeGroupVersion.String(),
+		Kind:       "EventSource",
+		Name:       es.Name,
+		UID:        es.UID,
+		Controller: &controller,
+	}
+}
@@ -0,0 +1,127 @@
+package eventsource
+
+import (
+	"context"
+	"testing"
+
+	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsureGeneratedSecret(t *testing.T) {
+	t.Parallel()
+
+	newES := func() *esv1alpha1.EventSource {
+		es := &esv1alpha1.EventSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar",
+				Namespace: "foo",
+				UID:       "1234",
+			},
+			Spec: esv1alpha1.EventSourceSpec{
+				Webhook: map[string]esv1alpha1.WebhookEventSource{"hook": {}},
+				Github:  map[string]esv1alpha1.GithubEventSource{"gh": {}},
+			},
+		}
+		eshandler.SetGeneratedSecrets(es)
+		return es
+	}
+
+	owned := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "bar-argoslower-webhooks",
+				Namespace:       "foo",
+				OwnerReferences: []metav1.OwnerReference{eventSourceOwnerReference(newES())},
+			},
+			Data: data,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		es        *esv1alpha1.EventSource
+		existing  []runtime.Object
+		noClient  bool
+		preserved map[string]string
+		noSecret  bool
+		err       bool
+		retryable bool
+	}{
+		{
+			name: "create",
+			es:   newES(),
+		},
+		{
+			name:     "add missing keys",
+			es:       newES(),
+			existing: []runtime.Object{owned(map[string][]byte{"webhook-hook": []byte("existing-token")})},
+			preserved: map[string]string{
+				"webhook-hook": "existing-token",
+			},
+		},
+		{
+			name: "not owned",
+			es:   newES(),
+			existing: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "bar-argoslower-webhooks", Namespace: "foo"},
+			}},
+			err: true,
+		},
+		{
+			name: "not generated",
+			es: &esv1alpha1.EventSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "foo"},
+			},
+			noSecret: true,
+		},
+		{
+			name:     "no client",
+			es:       newES(),
+			noClient: true,
+			noSecret: true,
+		},
+	}
+
+	for _, test := range tests {
+		client := fake.NewClientset(test.existing...)
+		e := &EventSourceIngressController{}
+		if !test.noClient {
+			e.SetSecretClient(client.CoreV1())
+		}
+
+		err := e.ensureGeneratedSecret(context.TODO(), test.es)
+		if test.err {
+			require.Error(t, err, test.name)
+			rerr, ok := err.(*perrs.RetryableError)
+			require.True(t, ok, test.name)
+			assert.Equal(t, test.retryable, rerr.IsRetryable(), test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+
+		secret, err := client.CoreV1().Secrets("foo").Get(context.TODO(), "bar-argoslower-webhooks", metav1.GetOptions{})
+		if test.noSecret {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+
+		assert.True(t, metav1.IsControlledBy(secret, test.es), test.name)
+		assert.Len(t, secret.Data, 2, test.name)
+		for _, key := range []string{"github-gh", "webhook-hook"} {
+			assert.GreaterOrEqual(t, len(secret.Data[key]), 12, test.name)
+		}
+		for key, value := range test.preserved {
+			assert.Equal(t, value, string(secret.Data[key]), test.name)
+		}
+	}
+}
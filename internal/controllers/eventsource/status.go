@@ -0,0 +1,155 @@
+package eventsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	eshandler "github.com/kanopy-platform/argoslower/internal/admission/eventsource"
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	esclient "github.com/argoproj/argo-events/pkg/client/clientset/versioned/typed/events/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultStatusAnnotationKey carries the JSON encoded IngressStatus of an EventSource with managed ingress.
+const DefaultStatusAnnotationKey string = eshandler.DefaultStatusAnnotationKey
+
+// statusRefreshPeriod bounds how long an unchanged status keeps its last sync time. Patching the status
+// on every resync would trigger another reconciliation of the EventSource each time.
+const statusRefreshPeriod = 10 * time.Minute
+
+// Event and status reasons of the ingress reconciliation.
+const (
+	ReasonConfigured             string = "IngressConfigured"
+	ReasonSecretGenerationFailed string = "SecretGenerationFailed"
+	ReasonApprovalLookupFailed   string = "ApprovalLookupFailed"
+	ReasonServiceNotFound        string = "ServiceNotFound"
+	ReasonWebhookExcluded        string = "WebhookExcluded"
+	ReasonNoExposedWebhooks      string = "NoExposedWebhooks"
+	ReasonUnsupportedHookType    string = "UnsupportedHookType"
+	ReasonIPFetchFailed          string = "IPFetchFailed"
+	ReasonConfigureFailed        string = "ConfigureFailed"
+	ReasonRemoveFailed           string = "RemoveFailed"
+)
+
+// IngressStatus reports the result of the last ingress reconciliation of an EventSource.
+type IngressStatus struct {
+	Ready        bool         `json:"ready"`
+	Reason       string       `json:"reason,omitempty"`
+	URL          string       `json:"url,omitempty"`
+	AllowedCIDRs int          `json:"allowedCIDRs"`
+	Excluded     []string     `json:"excluded,omitempty"`
+	LastError    string       `json:"lastError,omitempty"`
+	LastSync     *metav1.Time `json:"lastSync,omitempty"`
+}
+
+// fail records the reason and error of a failed reconciliation and returns the error. A reason code
+// carried by the error takes precedence over the reason.
+func (s *IngressStatus) fail(reason string, err error) error {
+	if r := perrs.ReasonOf(err); r != "" {
+		reason = r
+	}
+
+	s.Ready = false
+	s.Reason = reason
+	s.LastError = err.Error()
+	return err
+}
+
+// equal compares two statuses ignoring their sync times.
+func (s IngressStatus) equal(other IngressStatus) bool {
+	s.LastSync = nil
+	other.LastSync = nil
+
+	a, _ := json.Marshal(s)
+	b, _ := json.Marshal(other)
+	return string(a) == string(b)
+}
+
+// SetEventSourceClient configures the client used for writing the ingress status annotation of
+// EventSources. The status is only reported through events without a client.
+func (e *EventSourceIngressController) SetEventSourceClient(c esclient.EventSourcesGetter) {
+	e.esClient = c
+}
+
+// SetStatusAnnotationKey configures the EventSource annotation carrying the ingress status.
+func (e *EventSourceIngressController) SetStatusAnnotationKey(key string) {
+	if key != "" {
+		e.statusAnnotationKey = key
+	}
+}
+
+// recordStatus emits an event for a failed or newly configured ingress and patches the status
+// annotation of the EventSource when the status changed or its last sync time is stale. The
+// annotation is removed from EventSources without managed ingress.
+func (e *EventSourceIngressController) recordStatus(ctx context.Context, es *esv1alpha1.EventSource, status IngressStatus, now time.Time) error {
+	// deleted EventSources carry no status
+	if es == nil || es.DeletionTimestamp != nil {
+		return nil
+	}
+
+	value, ok := es.Annotations[e.statusAnnotationKey]
+	if status.Reason == "" {
+		// EventSources without managed ingress carry no status
+		if !ok || e.esClient == nil {
+			return nil
+		}
+		return e.patchStatus(ctx, es, nil)
+	}
+
+	previous := IngressStatus{}
+	if ok {
+		// an unparsable status is overwritten
+		_ = json.Unmarshal([]byte(value), &previous)
+	}
+
+	changed := !ok || !status.equal(previous)
+
+	// events are only emitted on changes so persistent failures don't emit one per resync
+	switch {
+	case changed && status.LastError != "":
+		e.event(es, corev1.EventTypeWarning, status.Reason, status.LastError)
+	case changed && status.Ready:
+		e.event(es, corev1.EventTypeNormal, status.Reason, fmt.Sprintf("Managed ingress is configured at %s for %d CIDRs", status.URL, status.AllowedCIDRs))
+	}
+
+	if e.esClient == nil {
+		return nil
+	}
+
+	if !changed && previous.LastSync != nil && now.Sub(previous.LastSync.Time) < statusRefreshPeriod {
+		return nil
+	}
+
+	status.LastSync = &metav1.Time{Time: now}
+	encoded, err := json.Marshal(status)
+	if err != nil {
+		return perrs.NewUnretryableError(err)
+	}
+
+	value = string(encoded)
+	return e.patchStatus(ctx, es, &value)
+}
+
+// patchStatus sets the status annotation of an EventSource to the value or removes it for nil values.
+func (e *EventSourceIngressController) patchStatus(ctx context.Context, es *esv1alpha1.EventSource, value *string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{e.statusAnnotationKey: value},
+		},
+	})
+	if err != nil {
+		return perrs.NewUnretryableError(err)
+	}
+
+	if _, err := e.esClient.EventSources(es.Namespace).Patch(ctx, es.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return perrs.NewRetryableError(fmt.Errorf("failed to patch the ingress status of eventsource %s/%s: %w", es.Namespace, es.Name, err))
+	}
+
+	return nil
+}
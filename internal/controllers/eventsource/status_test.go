@@ -0,0 +1,93 @@
+package eventsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	esfake "github.com/argoproj/argo-events/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ready := IngressStatus{Ready: true, Reason: ReasonConfigured, URL: "https://webhooks.example.com/ns/es", AllowedCIDRs: 2}
+
+	annotated := func(status IngressStatus, lastSync time.Time) map[string]string {
+		status.LastSync = &metav1.Time{Time: lastSync}
+		encoded, err := json.Marshal(status)
+		require.NoError(t, err)
+		return map[string]string{DefaultStatusAnnotationKey: string(encoded)}
+	}
+
+	failed := IngressStatus{}
+	_ = failed.fail(ReasonServiceNotFound, errors.New("no service"))
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		status      IngressStatus
+		wantPatch   bool
+		wantEvent   string
+	}{
+		{name: "unmanaged", status: IngressStatus{}},
+		{name: "opted out", annotations: annotated(ready, now.Add(-time.Minute)), status: IngressStatus{}, wantPatch: true},
+		{name: "first sync", status: ready, wantPatch: true, wantEvent: ReasonConfigured},
+		{name: "unchanged", annotations: annotated(ready, now.Add(-time.Minute)), status: ready},
+		{name: "stale sync time", annotations: annotated(ready, now.Add(-statusRefreshPeriod)), status: ready, wantPatch: true},
+		{name: "failed", annotations: annotated(ready, now.Add(-time.Minute)), status: failed, wantPatch: true, wantEvent: ReasonServiceNotFound},
+		{name: "still failing", annotations: annotated(failed, now.Add(-time.Minute)), status: failed},
+		{name: "still failing stale sync time", annotations: annotated(failed, now.Add(-statusRefreshPeriod)), status: failed, wantPatch: true},
+		{name: "unparsable status", annotations: map[string]string{DefaultStatusAnnotationKey: "{"}, status: ready, wantPatch: true, wantEvent: ReasonConfigured},
+	}
+
+	for _, test := range tests {
+		es := &esv1alpha1.EventSource{
+			ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns", Annotations: test.annotations},
+		}
+
+		client := esfake.NewSimpleClientset(es)
+		recorder := record.NewFakeRecorder(10)
+
+		controller := NewEventSourceIngressController(&FakeESLister{}, &FakeServiceLister{}, NewEventSourceIngressControllerConfig(), &FakeConfigurator{})
+		controller.SetEventSourceClient(client.ArgoprojV1alpha1())
+		controller.SetEventRecorder(recorder)
+
+		require.NoError(t, controller.recordStatus(context.TODO(), es, test.status, now), test.name)
+
+		if test.wantEvent != "" {
+			require.Len(t, recorder.Events, 1, test.name)
+			assert.Contains(t, <-recorder.Events, test.wantEvent, test.name)
+		} else {
+			assert.Empty(t, recorder.Events, test.name)
+		}
+
+		if !test.wantPatch {
+			assert.Empty(t, client.Actions(), test.name)
+			continue
+		}
+
+		require.Len(t, client.Actions(), 1, test.name)
+		patched, err := client.ArgoprojV1alpha1().EventSources("ns").Get(context.TODO(), "es", metav1.GetOptions{})
+		require.NoError(t, err, test.name)
+
+		if test.status.Reason == "" {
+			assert.NotContains(t, patched.Annotations, DefaultStatusAnnotationKey, test.name)
+			continue
+		}
+
+		got := IngressStatus{}
+		require.NoError(t, json.Unmarshal([]byte(patched.Annotations[DefaultStatusAnnotationKey]), &got), test.name)
+		assert.True(t, test.status.equal(got), test.name)
+		require.NotNil(t, got.LastSync, test.name)
+		assert.True(t, now.Equal(got.LastSync.Time), test.name)
+	}
+}
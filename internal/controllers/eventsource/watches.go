@@ -0,0 +1,42 @@
+package eventsource
+
+import (
+	"context"
+
+	ingresscommon "github.com/kanopy-platform/argoslower/pkg/ingress"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EventSourceRequests maps an object labeled for an EventSource back to a request reconciling that
+// EventSource. argo-events labels EventSource Services with the eventsource name only, objects without
+// the eventsource namespace label belong to an EventSource in their own namespace.
+func EventSourceRequests(ctx context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+
+	name := labels[esv1alpha1.LabelEventSourceName]
+	if name == "" {
+		return nil
+	}
+
+	namespace, ok := labels[ingresscommon.EventSourceNamespaceString]
+	if !ok {
+		namespace = obj.GetNamespace()
+	}
+	if namespace == "" {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}},
+	}
+}
+
+// EnqueueForEventSource enqueues the EventSource an object is labeled for.
+func EnqueueForEventSource() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(EventSourceRequests)
+}
@@ -0,0 +1,52 @@
+package eventsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestEventSourceRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		namespace string
+		labels    map[string]string
+		want      []reconcile.Request
+	}{
+		{
+			name:      "eventsource service",
+			namespace: "tenant",
+			labels:    map[string]string{"eventsource-name": "es"},
+			want:      []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "tenant", Name: "es"}}},
+		},
+		{
+			name:      "ingress resource",
+			namespace: "routing",
+			labels:    map[string]string{"eventsource-name": "es", "eventsource-namespace": "tenant"},
+			want:      []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "tenant", Name: "es"}}},
+		},
+		{
+			name:      "unlabeled",
+			namespace: "tenant",
+		},
+		{
+			name:   "empty namespace",
+			labels: map[string]string{"eventsource-name": "es", "eventsource-namespace": ""},
+		},
+	}
+
+	for _, test := range tests {
+		obj := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "obj", Namespace: test.namespace, Labels: test.labels},
+		}
+
+		assert.Equal(t, test.want, EventSourceRequests(context.TODO(), obj), test.name)
+	}
+}
@@ -1,12 +1,37 @@
 package errors
 
+import (
+	"errors"
+	"iter"
+	"time"
+)
+
 type Retryable interface {
 	IsRetryable() bool
 }
 
 type RetryableError struct {
-	err       error
-	retryable bool
+	err        error
+	retryable  bool
+	retryAfter time.Duration
+	reason     string
+}
+
+// Option configures optional retry hints of a RetryableError.
+type Option func(*RetryableError)
+
+// WithRetryAfter hints the error is retried after the duration instead of with backoff.
+func WithRetryAfter(d time.Duration) Option {
+	return func(e *RetryableError) {
+		e.retryAfter = d
+	}
+}
+
+// WithReason attaches a machine readable reason code to the error.
+func WithReason(reason string) Option {
+	return func(e *RetryableError) {
+		e.reason = reason
+	}
 }
 
 func (e *RetryableError) Unwrap() error {
@@ -21,16 +46,66 @@ func (e *RetryableError) IsRetryable() bool {
 	return e.retryable
 }
 
-func NewRetryableError(err error) *RetryableError {
-	return &RetryableError{
+// RetryAfter returns the duration after which the error should be retried, zero when unset.
+func (e *RetryableError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Reason returns the reason code of the error, empty when unset.
+func (e *RetryableError) Reason() string {
+	return e.reason
+}
+
+func NewRetryableError(err error, opts ...Option) *RetryableError {
+	return newError(err, true, opts)
+}
+
+func NewUnretryableError(err error, opts ...Option) *RetryableError {
+	return newError(err, false, opts)
+}
+
+func newError(err error, retryable bool, opts []Option) *RetryableError {
+	e := &RetryableError{
 		err:       err,
-		retryable: true,
+		retryable: retryable,
 	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
 }
 
-func NewUnretryableError(err error) *RetryableError {
-	return &RetryableError{
-		err:       err,
-		retryable: false,
+// RetryAfterOf returns the first retry after hint set on a RetryableError wrapped by err.
+func RetryAfterOf(err error) time.Duration {
+	for rerr := range chain(err) {
+		if rerr.retryAfter > 0 {
+			return rerr.retryAfter
+		}
+	}
+	return 0
+}
+
+// ReasonOf returns the first reason code set on a RetryableError wrapped by err.
+func ReasonOf(err error) string {
+	for rerr := range chain(err) {
+		if rerr.reason != "" {
+			return rerr.reason
+		}
+	}
+	return ""
+}
+
+// chain yields the RetryableErrors wrapped by err from the outermost to the innermost.
+func chain(err error) iter.Seq[*RetryableError] {
+	return func(yield func(*RetryableError) bool) {
+		for err != nil {
+			var rerr *RetryableError
+			if !errors.As(err, &rerr) || !yield(rerr) {
+				return
+			}
+			err = rerr.err
+		}
 	}
 }
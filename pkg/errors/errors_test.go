@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryableErrorOptions(t *testing.T) {
+	t.Parallel()
+
+	base := errors.New("test error")
+
+	err := NewRetryableError(base)
+	assert.True(t, err.IsRetryable())
+	assert.Zero(t, err.RetryAfter())
+	assert.Empty(t, err.Reason())
+
+	err = NewRetryableError(base, WithRetryAfter(5*time.Second), WithReason("ServiceNotFound"))
+	assert.True(t, err.IsRetryable())
+	assert.Equal(t, 5*time.Second, err.RetryAfter())
+	assert.Equal(t, "ServiceNotFound", err.Reason())
+	assert.ErrorIs(t, err, base)
+
+	unretryable := NewUnretryableError(base, WithReason("Invalid"))
+	assert.False(t, unretryable.IsRetryable())
+	assert.Equal(t, "Invalid", unretryable.Reason())
+}
+
+func TestHintsOf(t *testing.T) {
+	t.Parallel()
+
+	inner := NewRetryableError(errors.New("throttled"), WithRetryAfter(time.Minute), WithReason("Throttled"))
+
+	tests := []struct {
+		name       string
+		err        error
+		retryAfter time.Duration
+		reason     string
+	}{
+		{name: "nil"},
+		{name: "plain error", err: errors.New("plain")},
+		{name: "direct", err: inner, retryAfter: time.Minute, reason: "Throttled"},
+		{name: "wrapped", err: fmt.Errorf("sync: %w", inner), retryAfter: time.Minute, reason: "Throttled"},
+		{name: "wrapped by a retryable error without hints", err: NewRetryableError(fmt.Errorf("sync: %w", inner)), retryAfter: time.Minute, reason: "Throttled"},
+		{name: "outer hints win", err: NewRetryableError(inner, WithReason("IPFetchFailed")), retryAfter: time.Minute, reason: "IPFetchFailed"},
+		{name: "joined", err: NewRetryableError(errors.Join(errors.New("other"), inner)), retryAfter: time.Minute, reason: "Throttled"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.retryAfter, RetryAfterOf(test.err), test.name)
+		assert.Equal(t, test.reason, ReasonOf(test.err), test.name)
+	}
+}
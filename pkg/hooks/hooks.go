@@ -0,0 +1,118 @@
+package hooks
+
+import (
+	"sort"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+)
+
+// Type identifies the EventSource spec field a webhook configuration was sourced from.
+type Type string
+
+const (
+	Webhook Type = "webhook"
+	Github  Type = "github"
+	Slack   Type = "slack"
+	SNS     Type = "sns"
+)
+
+// Hook is a named webhook context of a supported EventSource type.
+type Hook struct {
+	Type    Type
+	Name    string
+	Context *esv1alpha1.WebhookContext
+}
+
+// List returns the webhook contexts of all supported EventSource types sorted
+// by type and name. Entries without a webhook context are skipped.
+func List(es *esv1alpha1.EventSource) []Hook {
+	out := []Hook{}
+	if es == nil {
+		return out
+	}
+
+	for name, spec := range es.Spec.Webhook {
+		out = append(out, Hook{Type: Webhook, Name: name, Context: spec.WebhookContext.DeepCopy()})
+	}
+
+	for name, spec := range es.Spec.Github {
+		out = appendContext(out, Github, name, spec.Webhook)
+	}
+
+	for name, spec := range es.Spec.Slack {
+		out = appendContext(out, Slack, name, spec.Webhook)
+	}
+
+	for name, spec := range es.Spec.SNS {
+		out = appendContext(out, SNS, name, spec.Webhook)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Name < out[j].Name
+	})
+
+	return out
+}
+
+func appendContext(in []Hook, t Type, name string, ctx *esv1alpha1.WebhookContext) []Hook {
+	if ctx == nil {
+		return in
+	}
+	return append(in, Hook{Type: t, Name: name, Context: ctx.DeepCopy()})
+}
+
+// Exposed reports whether the EventSource serves webhooks. This includes webhook based
+// EventSource types without managed ingress support.
+func Exposed(es *esv1alpha1.EventSource) bool {
+	return len(List(es)) > 0 || HasUnsupported(es)
+}
+
+// HasUnsupported reports whether the EventSource serves webhooks of types without managed
+// ingress support. Stripe webhooks are unsupported as argo-events does not verify their
+// payload signatures.
+func HasUnsupported(es *esv1alpha1.EventSource) bool {
+	if es == nil {
+		return false
+	}
+
+	for _, spec := range es.Spec.Gitlab {
+		if spec.Webhook != nil {
+			return true
+		}
+	}
+
+	for _, spec := range es.Spec.Bitbucket {
+		if spec.Webhook != nil {
+			return true
+		}
+	}
+
+	for _, spec := range es.Spec.BitbucketServer {
+		if spec.Webhook != nil {
+			return true
+		}
+	}
+
+	for _, spec := range es.Spec.Gerrit {
+		if spec.Webhook != nil {
+			return true
+		}
+	}
+
+	for _, spec := range es.Spec.StorageGrid {
+		if spec.Webhook != nil {
+			return true
+		}
+	}
+
+	for _, spec := range es.Spec.Stripe {
+		if spec.Webhook != nil {
+			return true
+		}
+	}
+
+	return false
+}
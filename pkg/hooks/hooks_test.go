@@ -0,0 +1,117 @@
+package hooks
+
+import (
+	"testing"
+
+	esv1alpha1 "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	es := &esv1alpha1.EventSource{
+		Spec: esv1alpha1.EventSourceSpec{
+			Webhook: map[string]esv1alpha1.WebhookEventSource{
+				"b": esv1alpha1.WebhookEventSource{
+					WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/b", Port: "12000"},
+				},
+				"a": esv1alpha1.WebhookEventSource{
+					WebhookContext: esv1alpha1.WebhookContext{Endpoint: "/a", Port: "12000"},
+				},
+			},
+			Github: map[string]esv1alpha1.GithubEventSource{
+				"gh": esv1alpha1.GithubEventSource{
+					Webhook: &esv1alpha1.WebhookContext{Endpoint: "/gh", Port: "13000"},
+				},
+				"nowebhook": esv1alpha1.GithubEventSource{},
+			},
+			Slack: map[string]esv1alpha1.SlackEventSource{
+				"slack": esv1alpha1.SlackEventSource{
+					Webhook: &esv1alpha1.WebhookContext{Endpoint: "/slack", Port: "14000"},
+				},
+			},
+			SNS: map[string]esv1alpha1.SNSEventSource{
+				"sns": esv1alpha1.SNSEventSource{
+					Webhook: &esv1alpha1.WebhookContext{Endpoint: "/sns", Port: "16000"},
+				},
+			},
+		},
+	}
+
+	out := List(es)
+	assert.Len(t, out, 5)
+
+	expected := []struct {
+		t    Type
+		name string
+		path string
+	}{
+		{Github, "gh", "/gh"},
+		{Slack, "slack", "/slack"},
+		{SNS, "sns", "/sns"},
+		{Webhook, "a", "/a"},
+		{Webhook, "b", "/b"},
+	}
+
+	for i, e := range expected {
+		assert.Equal(t, e.t, out[i].Type)
+		assert.Equal(t, e.name, out[i].Name)
+		assert.Equal(t, e.path, out[i].Context.Endpoint)
+	}
+
+	assert.Empty(t, List(nil))
+}
+
+func TestExposed(t *testing.T) {
+	t.Parallel()
+
+	webhook := &esv1alpha1.WebhookContext{Endpoint: "/hook", Port: "12000"}
+
+	tests := map[string]struct {
+		spec     esv1alpha1.EventSourceSpec
+		expected bool
+	}{
+		"none": {},
+		"github": {
+			spec:     esv1alpha1.EventSourceSpec{Github: map[string]esv1alpha1.GithubEventSource{"gh": {Webhook: webhook}}},
+			expected: true,
+		},
+		"github without webhook": {
+			spec: esv1alpha1.EventSourceSpec{Github: map[string]esv1alpha1.GithubEventSource{"gh": {}}},
+		},
+		"gitlab": {
+			spec:     esv1alpha1.EventSourceSpec{Gitlab: map[string]esv1alpha1.GitlabEventSource{"gl": {Webhook: webhook}}},
+			expected: true,
+		},
+		"bitbucket": {
+			spec:     esv1alpha1.EventSourceSpec{Bitbucket: map[string]esv1alpha1.BitbucketEventSource{"bb": {Webhook: webhook}}},
+			expected: true,
+		},
+		"bitbucketserver": {
+			spec:     esv1alpha1.EventSourceSpec{BitbucketServer: map[string]esv1alpha1.BitbucketServerEventSource{"bbs": {Webhook: webhook}}},
+			expected: true,
+		},
+		"gerrit": {
+			spec:     esv1alpha1.EventSourceSpec{Gerrit: map[string]esv1alpha1.GerritEventSource{"g": {Webhook: webhook}}},
+			expected: true,
+		},
+		"storagegrid": {
+			spec:     esv1alpha1.EventSourceSpec{StorageGrid: map[string]esv1alpha1.StorageGridEventSource{"sg": {Webhook: webhook}}},
+			expected: true,
+		},
+		"stripe": {
+			spec:     esv1alpha1.EventSourceSpec{Stripe: map[string]esv1alpha1.StripeEventSource{"s": {Webhook: webhook}}},
+			expected: true,
+		},
+		"calendar": {
+			spec: esv1alpha1.EventSourceSpec{Calendar: map[string]esv1alpha1.CalendarEventSource{"c": {}}},
+		},
+	}
+
+	for name, test := range tests {
+		es := &esv1alpha1.EventSource{Spec: test.spec}
+		assert.Equal(t, test.expected, Exposed(es), name)
+		assert.Equal(t, test.expected && len(List(es)) == 0, HasUnsupported(es), name)
+	}
+}
@@ -1,11 +1,88 @@
 package ingress
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
 const (
 	EventSourceNameString      string = "eventsource-name"
 	EventSourceNamespaceString string = "eventsource-namespace"
 )
 
+// GeneratedSelector selects the ingress resources generated for any EventSource.
+const GeneratedSelector string = EventSourceNameString + "," + EventSourceNamespaceString
+
+// DesiredStateAnnotationKey carries a hash of the rendered state of an ingress resource. Resources whose
+// annotation and live state match the desired hash are not written again.
+const DesiredStateAnnotationKey string = "v1alpha1.argoslower.kanopy-platform/desired-state"
+
+// maxNameLength is the maximum length of a DNS subdomain resource name
+const maxNameLength = 253
+
+// NamedPath is a webhook endpoint and the known source its requests are allowed from
 type NamedPath struct {
-	Name string
-	Path string
+	Name   string
+	Path   string
+	Source string
+}
+
+// NormalizeEndpoint returns the path argo-events serves a webhook endpoint at, prefixing the
+// endpoint with a slash when missing.
+func NormalizeEndpoint(endpoint string) string {
+	if strings.HasPrefix(endpoint, "/") {
+		return endpoint
+	}
+	return "/" + endpoint
+}
+
+// NestedEndpoint reports whether endpoint is routed below parent. The ingress of parent also
+// matches its sub paths, so requests to endpoint are subject to the rules of both.
+func NestedEndpoint(parent, endpoint string) bool {
+	return strings.HasPrefix(NormalizeEndpoint(endpoint), NormalizeEndpoint(parent)+"/")
+}
+
+// ResourceName returns the name of the ingress resources generated for an EventSource. The
+// namespace and name are joined with a hash of the namespaced name, as the joined names alone
+// are not unique, i.e. a-b/c and a/b-c.
+func ResourceName(es types.NamespacedName) string {
+	sum := sha256.Sum256([]byte(es.String()))
+	hash := hex.EncodeToString(sum[:])[:8]
+
+	prefix := fmt.Sprintf("%s-%s", es.Namespace, es.Name)
+	if len(prefix) > maxNameLength-len(hash)-1 {
+		prefix = strings.TrimRight(prefix[:maxNameLength-len(hash)-1], "-.")
+	}
+
+	return fmt.Sprintf("%s-%s", prefix, hash)
+}
+
+// EventSourceLabels returns the labels identifying the EventSource of a generated ingress resource.
+func EventSourceLabels(es types.NamespacedName) map[string]string {
+	return map[string]string{
+		EventSourceNameString:      es.Name,
+		EventSourceNamespaceString: es.Namespace,
+	}
+}
+
+// EventSourceOf returns the EventSource identified by the labels of a generated ingress resource.
+func EventSourceOf(labels map[string]string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: labels[EventSourceNamespaceString],
+		Name:      labels[EventSourceNameString],
+	}
+}
+
+// SameEventSource reports whether two label sets identify the same EventSource.
+func SameEventSource(a, b map[string]string) bool {
+	return EventSourceOf(a) == EventSourceOf(b)
+}
+
+// LabelSelector selects the ingress resources generated for an EventSource.
+func LabelSelector(es types.NamespacedName) string {
+	return fmt.Sprintf("%s=%s,%s=%s", EventSourceNameString, es.Name, EventSourceNamespaceString, es.Namespace)
 }
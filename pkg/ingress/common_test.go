@@ -0,0 +1,56 @@
+package ingress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNormalizeEndpoint(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/push", NormalizeEndpoint("/push"))
+	assert.Equal(t, "/push", NormalizeEndpoint("push"))
+	assert.Equal(t, "/", NormalizeEndpoint(""))
+}
+
+func TestNestedEndpoint(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, NestedEndpoint("/push", "/push/jira"))
+	assert.True(t, NestedEndpoint("push", "/push/jira/x"))
+	assert.False(t, NestedEndpoint("/push", "/push"))
+	assert.False(t, NestedEndpoint("/push", "/pushed"))
+	assert.False(t, NestedEndpoint("/push/jira", "/push"))
+}
+
+func TestResourceName(t *testing.T) {
+	t.Parallel()
+
+	a := ResourceName(types.NamespacedName{Namespace: "a-b", Name: "c"})
+	b := ResourceName(types.NamespacedName{Namespace: "a", Name: "b-c"})
+
+	assert.True(t, strings.HasPrefix(a, "a-b-c-"))
+	assert.True(t, strings.HasPrefix(b, "a-b-c-"))
+	assert.NotEqual(t, a, b)
+	assert.Len(t, a, len("a-b-c-")+8)
+	assert.Equal(t, a, ResourceName(types.NamespacedName{Namespace: "a-b", Name: "c"}))
+
+	long := ResourceName(types.NamespacedName{Namespace: strings.Repeat("n", 63), Name: strings.Repeat("e", 253)})
+	assert.LessOrEqual(t, len(long), 253)
+	assert.True(t, strings.HasPrefix(long, strings.Repeat("n", 63)+"-e"))
+}
+
+func TestEventSourceLabels(t *testing.T) {
+	t.Parallel()
+
+	es := types.NamespacedName{Namespace: "ns", Name: "es"}
+	labels := EventSourceLabels(es)
+	assert.Equal(t, es, EventSourceOf(labels))
+	assert.Equal(t, "eventsource-name=es,eventsource-namespace=ns", LabelSelector(es))
+
+	assert.True(t, SameEventSource(labels, map[string]string{EventSourceNameString: "es", EventSourceNamespaceString: "ns", "team": "a"}))
+	assert.False(t, SameEventSource(labels, EventSourceLabels(types.NamespacedName{Namespace: "ns-es", Name: ""})))
+}
@@ -0,0 +1,217 @@
+package gatewayapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
+	common "github.com/kanopy-platform/argoslower/pkg/ingress"
+	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+)
+
+// GatewayAPIClient renders Gateway API HTTPRoutes and istio AuthorizationPolicies attached to a Gateway
+// for the webhooks of EventSources.
+type GatewayAPIClient struct {
+	client  dynamic.Interface
+	listers map[schema.GroupVersionResource]cache.GenericLister
+}
+
+// rendered is a rendered object, its resource and the live object when it exists.
+type rendered struct {
+	gvr  schema.GroupVersionResource
+	obj  *unstructured.Unstructured
+	live *unstructured.Unstructured
+}
+
+func NewClient(dc dynamic.Interface) *GatewayAPIClient {
+	return &GatewayAPIClient{
+		client:  dc,
+		listers: map[schema.GroupVersionResource]cache.GenericLister{},
+	}
+}
+
+// SetLister configures the informer cache live objects of a resource are read from. Resources without
+// a lister are read through the API server.
+func (g *GatewayAPIClient) SetLister(gvr schema.GroupVersionResource, lister cache.GenericLister) {
+	g.listers[gvr] = lister
+}
+
+// get returns the live object of a resource from the informer cache when configured.
+func (g *GatewayAPIClient) get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	lister, ok := g.listers[gvr]
+	if !ok {
+		return g.client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+
+	obj, err := lister.ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T of %s %s/%s", obj, gvr.Resource, namespace, name)
+	}
+	return u, nil
+}
+
+func (g *GatewayAPIClient) Configure(ctx context.Context, config *v1.EventSourceIngressConfig) ([]types.NamespacedName, error) {
+	log := log.FromContext(ctx)
+	out := []types.NamespacedName{}
+
+	if config == nil {
+		return out, perrs.NewUnretryableError(fmt.Errorf("nil config"))
+	}
+
+	cidrs := map[string][]string{}
+	for source, getter := range config.IPGetters {
+		if getter == nil {
+			return out, perrs.NewUnretryableError(fmt.Errorf("no IPGetter configured for source %s of %s", source, config.Eventsource.String()))
+		}
+
+		ips, err := getter.GetIPs()
+		if err != nil {
+			log.V(5).Info(fmt.Sprintf("Failed to source IPs for %s from %s: %s", config.Eventsource.String(), source, err.Error()))
+			return out, perrs.NewRetryableError(err)
+		}
+
+		if len(ips) == 0 {
+			e := fmt.Errorf("failed to source IPs for %s from %s", config.Eventsource.String(), source)
+			log.V(1).Info(e.Error())
+			return out, perrs.NewRetryableError(e)
+		}
+		cidrs[source] = ips
+	}
+
+	route, err := RenderHTTPRoute(config.BaseURL, config.Gateway, config.Service, config.Eventsource, config.Endpoints)
+	if err != nil {
+		return out, perrs.NewUnretryableError(err)
+	}
+
+	ap, err := RenderAuthorizationPolicy(config.BaseURL, config.Gateway, config.Eventsource, cidrs, config.Endpoints)
+	if err != nil {
+		return out, perrs.NewUnretryableError(err)
+	}
+
+	objects := []rendered{
+		{gvr: HTTPRouteResource, obj: route},
+		{gvr: AuthorizationPolicyResource, obj: ap},
+	}
+
+	// HTTPRoutes reference Services of other namespaces through a ReferenceGrant
+	if config.Service.Namespace != config.Gateway.Namespace {
+		grant, err := RenderReferenceGrant(config.Gateway, config.Service, config.Eventsource)
+		if err != nil {
+			return out, perrs.NewUnretryableError(err)
+		}
+		objects = append(objects, rendered{gvr: ReferenceGrantResource, obj: grant})
+	}
+
+	// ownership of every object is verified before any of them is written
+	for i, r := range objects {
+		existing, err := g.get(ctx, r.gvr, r.obj.GetNamespace(), r.obj.GetName())
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return out, perrs.NewRetryableError(err)
+		}
+		if err != nil {
+			continue
+		}
+
+		// force applies take over objects regardless of their owner
+		if !common.SameEventSource(existing.GetLabels(), r.obj.GetLabels()) {
+			return out, perrs.NewUnretryableError(fmt.Errorf("%s %s/%s belongs to another eventsource", r.gvr.Resource, r.obj.GetNamespace(), r.obj.GetName()))
+		}
+		objects[i].live = existing
+	}
+
+	applyOpts := metav1.ApplyOptions{
+		Force:        true,
+		FieldManager: "argoslower",
+	}
+
+	for _, r := range objects {
+		// unchanged objects are skipped, the live objects carry the hash they were applied with
+		if r.live == nil || !upToDate(r.obj, r.live) {
+			if _, err := g.client.Resource(r.gvr).Namespace(r.obj.GetNamespace()).Apply(ctx, r.obj.GetName(), r.obj, applyOpts); err != nil {
+				return out, perrs.NewRetryableError(fmt.Errorf("failed to apply %s %s/%s: %w", r.gvr.Resource, r.obj.GetNamespace(), r.obj.GetName(), err))
+			}
+		}
+		out = append(out, types.NamespacedName{Namespace: r.obj.GetNamespace(), Name: r.obj.GetName()})
+	}
+
+	return out, nil
+}
+
+func (g *GatewayAPIClient) Remove(ctx context.Context, config *v1.EventSourceIngressConfig) error {
+	if config == nil || config.Eventsource.Name == "" || config.Eventsource.Namespace == "" || config.Gateway.Namespace == "" {
+		return perrs.NewUnretryableError(fmt.Errorf("empty namespaced name for event source"))
+	}
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: common.LabelSelector(config.Eventsource),
+	}
+
+	var errs error
+	for _, r := range []struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+	}{
+		{gvr: HTTPRouteResource, namespace: config.Gateway.Namespace},
+		{gvr: AuthorizationPolicyResource, namespace: config.Gateway.Namespace},
+		{gvr: ReferenceGrantResource, namespace: config.Eventsource.Namespace},
+	} {
+		err := g.client.Resource(r.gvr).Namespace(r.namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOpts)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", r.gvr.Resource, err))
+		}
+	}
+
+	if errs != nil {
+		return perrs.NewRetryableError(fmt.Errorf("deletion errors for selector %s: %w", listOpts.LabelSelector, errs))
+	}
+
+	return nil
+}
+
+// ListEventSources returns the EventSources labeled on the HTTPRoutes and AuthorizationPolicies of the
+// gateway namespace.
+func (g *GatewayAPIClient) ListEventSources(ctx context.Context, config *v1.EventSourceIngressConfig) ([]types.NamespacedName, error) {
+	if config == nil || config.Gateway.Namespace == "" {
+		return nil, perrs.NewUnretryableError(fmt.Errorf("empty gateway namespace"))
+	}
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: common.GeneratedSelector,
+	}
+
+	found := map[types.NamespacedName]bool{}
+	for _, gvr := range []schema.GroupVersionResource{HTTPRouteResource, AuthorizationPolicyResource} {
+		list, err := g.client.Resource(gvr).Namespace(config.Gateway.Namespace).List(ctx, listOpts)
+		if err != nil {
+			return nil, perrs.NewRetryableError(err)
+		}
+
+		for _, item := range list.Items {
+			found[common.EventSourceOf(item.GetLabels())] = true
+		}
+	}
+
+	out := make([]types.NamespacedName, 0, len(found))
+	for nsn := range found {
+		out = append(out, nsn)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].String() < out[b].String() })
+
+	return out, nil
+}
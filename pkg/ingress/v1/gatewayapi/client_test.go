@@ -0,0 +1,349 @@
+package gatewayapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	common "github.com/kanopy-platform/argoslower/pkg/ingress"
+	v1 "github.com/kanopy-platform/argoslower/pkg/ingress/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+type fakeIPGetter struct {
+	ips []string
+}
+
+func (f *fakeIPGetter) GetIPs() ([]string, error) {
+	return f.ips, nil
+}
+
+// newFakeClient returns a dynamic fake client creating or replacing objects on server side apply.
+func newFakeClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		HTTPRouteResource:           "HTTPRouteList",
+		AuthorizationPolicyResource: "AuthorizationPolicyList",
+		ReferenceGrantResource:      "ReferenceGrantList",
+	}, objects...)
+
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch, ok := action.(k8stesting.PatchActionImpl)
+		if !ok || patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &obj.Object); err != nil {
+			return true, nil, err
+		}
+
+		_, err := client.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if k8serrors.IsNotFound(err) {
+			return true, obj, client.Tracker().Create(patch.GetResource(), obj, patch.GetNamespace())
+		}
+		return true, obj, client.Tracker().Update(patch.GetResource(), obj, patch.GetNamespace())
+	})
+
+	return client
+}
+
+func newConfig() *v1.EventSourceIngressConfig {
+	return &v1.EventSourceIngressConfig{
+		IPGetters: map[string]v1.IPGetter{
+			"github": &fakeIPGetter{ips: []string{"1.2.3.4/32"}},
+			"jira":   &fakeIPGetter{ips: []string{"10.0.0.0/8"}},
+		},
+		Eventsource: types.NamespacedName{Namespace: "destination", Name: "eventsource"},
+		Endpoints: map[string][]common.NamedPath{
+			"12000": []common.NamedPath{
+				common.NamedPath{Name: "github", Path: "/github", Source: "github"},
+			},
+			"12001": []common.NamedPath{
+				common.NamedPath{Name: "jira", Path: "/jira", Source: "jira"},
+			},
+		},
+		AdminNamespace: "routing",
+		BaseURL:        "webhooks.example.com",
+		Gateway:        types.NamespacedName{Namespace: "routing-rules", Name: "webhooks"},
+		Service:        types.NamespacedName{Namespace: "destination", Name: "eventsource-eventsource-svc"},
+	}
+}
+
+func applies(client *dynamicfake.FakeDynamicClient) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if patch, ok := action.(k8stesting.PatchActionImpl); ok && patch.GetPatchType() == types.ApplyPatchType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRenderHTTPRoute(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+
+	route, err := RenderHTTPRoute(config.BaseURL, config.Gateway, config.Service, config.Eventsource, config.Endpoints)
+	require.NoError(t, err)
+
+	assert.Equal(t, "HTTPRoute", route.GetKind())
+	assert.Equal(t, "gateway.networking.k8s.io/v1", route.GetAPIVersion())
+	assert.Equal(t, common.ResourceName(config.Eventsource), route.GetName())
+	assert.Equal(t, config.Gateway.Namespace, route.GetNamespace())
+	assert.Equal(t, map[string]string{
+		common.EventSourceNameString:      "eventsource",
+		common.EventSourceNamespaceString: "destination",
+	}, route.GetLabels())
+	assert.NotEmpty(t, route.GetAnnotations()[common.DesiredStateAnnotationKey])
+
+	hostnames, _, err := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"webhooks.example.com"}, hostnames)
+
+	parentRefs, _, err := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"group":     "gateway.networking.k8s.io",
+		"kind":      "Gateway",
+		"namespace": "routing-rules",
+		"name":      "webhooks",
+	}}, parentRefs)
+
+	rules, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+	require.NoError(t, err)
+	// a short bearer, an exact and a prefix rule per endpoint
+	require.Len(t, rules, 6)
+
+	shortBearer := rules[0].(map[string]interface{})
+	assert.NotContains(t, shortBearer, "backendRefs")
+	matches := shortBearer["matches"].([]interface{})
+	require.Len(t, matches, 2)
+	for _, match := range matches {
+		headers := match.(map[string]interface{})["headers"].([]interface{})
+		assert.Equal(t, "RegularExpression", headers[0].(map[string]interface{})["type"])
+		assert.Equal(t, shortBearerRegex, headers[0].(map[string]interface{})["value"])
+	}
+
+	exact := rules[1].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "Exact", "value": "/destination/eventsource/github"}, exact["matches"].([]interface{})[0].(map[string]interface{})["path"])
+	backend := exact["backendRefs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "eventsource-eventsource-svc", backend["name"])
+	assert.Equal(t, "destination", backend["namespace"])
+	assert.Equal(t, int64(12000), backend["port"])
+
+	prefix := rules[2].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "PathPrefix", "value": "/destination/eventsource/github"}, prefix["matches"].([]interface{})[0].(map[string]interface{})["path"])
+	// the trailing slash url /destination/eventsource/github/ is rewritten to /github/
+	rewrite := prefix["filters"].([]interface{})[0].(map[string]interface{})["urlRewrite"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "ReplacePrefixMatch", "replacePrefixMatch": "/github"}, rewrite["path"])
+
+	_, err = RenderHTTPRoute(config.BaseURL, config.Gateway, config.Service, config.Eventsource, nil)
+	assert.Error(t, err)
+}
+
+func TestRenderAuthorizationPolicy(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	cidrs := map[string][]string{
+		"github": []string{"1.2.3.4/32"},
+		"jira":   []string{"10.0.0.0/8"},
+	}
+
+	ap, err := RenderAuthorizationPolicy(config.BaseURL, config.Gateway, config.Eventsource, cidrs, config.Endpoints)
+	require.NoError(t, err)
+
+	assert.Equal(t, "security.istio.io/v1", ap.GetAPIVersion())
+	assert.Equal(t, config.Gateway.Namespace, ap.GetNamespace())
+
+	action, _, err := unstructured.NestedString(ap.Object, "spec", "action")
+	require.NoError(t, err)
+	assert.Equal(t, "DENY", action)
+
+	targetRefs, _, err := unstructured.NestedSlice(ap.Object, "spec", "targetRefs")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"group": "gateway.networking.k8s.io",
+		"kind":  "Gateway",
+		"name":  "webhooks",
+	}}, targetRefs)
+
+	rules, _, err := unstructured.NestedSlice(ap.Object, "spec", "rules")
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	github := rules[0].(map[string]interface{})
+	source := github["from"].([]interface{})[0].(map[string]interface{})["source"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"1.2.3.4/32"}, source["notIpBlocks"])
+	operation := github["to"].([]interface{})[0].(map[string]interface{})["operation"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"webhooks.example.com", "webhooks.example.com:*"}, operation["hosts"])
+	assert.Equal(t, []interface{}{"/destination/eventsource/github", "/destination/eventsource/github/*"}, operation["paths"])
+
+	delete(cidrs, "jira")
+	_, err = RenderAuthorizationPolicy(config.BaseURL, config.Gateway, config.Eventsource, cidrs, config.Endpoints)
+	assert.Error(t, err)
+
+	_, err = RenderAuthorizationPolicy(config.BaseURL, config.Gateway, config.Eventsource, cidrs, nil)
+	assert.Error(t, err)
+}
+
+func TestConfigure(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+	config := newConfig()
+	client := newFakeClient()
+	g := NewClient(client)
+
+	out, err := g.Configure(ctx, config)
+	require.NoError(t, err)
+	name := common.ResourceName(config.Eventsource)
+	assert.Equal(t, []types.NamespacedName{
+		{Namespace: "routing-rules", Name: name},
+		{Namespace: "routing-rules", Name: name},
+		{Namespace: "destination", Name: name},
+	}, out)
+	assert.Equal(t, 3, applies(client))
+
+	grant, err := client.Resource(ReferenceGrantResource).Namespace("destination").Get(ctx, name, metav1.GetOptions{})
+	require.NoError(t, err)
+	from, _, err := unstructured.NestedSlice(grant.Object, "spec", "from")
+	require.NoError(t, err)
+	assert.Equal(t, "routing-rules", from[0].(map[string]interface{})["namespace"])
+
+	// unchanged objects are not written again
+	_, err = g.Configure(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, 3, applies(client))
+
+	// drifted objects are written again
+	route, err := client.Resource(HTTPRouteResource).Namespace("routing-rules").Get(ctx, name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedStringSlice(route.Object, []string{"other.example.com"}, "spec", "hostnames"))
+	_, err = client.Resource(HTTPRouteResource).Namespace("routing-rules").Update(ctx, route, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	_, err = g.Configure(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, 4, applies(client))
+
+	// changed CIDRs change the desired state
+	config.IPGetters["github"] = &fakeIPGetter{ips: []string{"5.6.7.8/32"}}
+	_, err = g.Configure(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, 5, applies(client))
+
+	// services in the gateway namespace need no grant
+	config = newConfig()
+	config.Eventsource.Name = "local"
+	config.Service.Namespace = config.Gateway.Namespace
+	out, err = g.Configure(ctx, config)
+	require.NoError(t, err)
+	assert.Len(t, out, 2)
+}
+
+func TestConfigureListers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+	config := newConfig()
+	client := newFakeClient()
+	g := NewClient(client)
+	_, err := g.Configure(ctx, config)
+	require.NoError(t, err)
+
+	// listers are populated with the live objects like the informers of the objects
+	for _, gvr := range []schema.GroupVersionResource{HTTPRouteResource, AuthorizationPolicyResource, ReferenceGrantResource} {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		list, err := client.Resource(gvr).List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		for i := range list.Items {
+			require.NoError(t, indexer.Add(&list.Items[i]))
+		}
+		g.SetLister(gvr, cache.NewGenericLister(indexer, gvr.GroupResource()))
+	}
+
+	client.ClearActions()
+	_, err = g.Configure(ctx, config)
+	require.NoError(t, err)
+
+	// unchanged objects are neither read from the API server nor written
+	assert.Empty(t, client.Actions())
+}
+
+func TestConfigureOwnership(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	route, err := RenderHTTPRoute(config.BaseURL, config.Gateway, config.Service, types.NamespacedName{Namespace: "other", Name: "eventsource"}, config.Endpoints)
+	require.NoError(t, err)
+	route.SetName(common.ResourceName(config.Eventsource))
+
+	client := newFakeClient(route)
+	_, err = NewClient(client).Configure(context.TODO(), config)
+	assert.Error(t, err)
+	assert.Equal(t, 0, applies(client))
+
+	_, err = NewClient(client).Configure(context.TODO(), nil)
+	assert.Error(t, err)
+}
+
+func TestRemove(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient()
+	g := NewClient(client)
+
+	require.NoError(t, g.Remove(context.TODO(), newConfig()))
+
+	deleted := map[string]string{}
+	for _, action := range client.Actions() {
+		dc, ok := action.(k8stesting.DeleteCollectionActionImpl)
+		if !ok {
+			continue
+		}
+		assert.Equal(t, "eventsource-name=eventsource,eventsource-namespace=destination", dc.GetListRestrictions().Labels.String())
+		deleted[dc.GetResource().Resource] = dc.GetNamespace()
+	}
+	assert.Equal(t, map[string]string{
+		"httproutes":            "routing-rules",
+		"authorizationpolicies": "routing-rules",
+		"referencegrants":       "destination",
+	}, deleted)
+
+	assert.Error(t, g.Remove(context.TODO(), &v1.EventSourceIngressConfig{}))
+}
+
+func TestListEventSources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+	client := newFakeClient()
+	g := NewClient(client)
+
+	for _, nsn := range []types.NamespacedName{{Namespace: "b", Name: "es"}, {Namespace: "a", Name: "es"}} {
+		config := newConfig()
+		config.Eventsource = nsn
+		config.Service.Namespace = nsn.Namespace
+		_, err := g.Configure(ctx, config)
+		require.NoError(t, err)
+	}
+
+	out, err := g.ListEventSources(ctx, newConfig())
+	require.NoError(t, err)
+	assert.Equal(t, []types.NamespacedName{{Namespace: "a", Name: "es"}, {Namespace: "b", Name: "es"}}, out)
+
+	_, err = g.ListEventSources(ctx, &v1.EventSourceIngressConfig{})
+	assert.Error(t, err)
+}
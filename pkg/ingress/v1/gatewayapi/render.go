@@ -0,0 +1,298 @@
+package gatewayapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	common "github.com/kanopy-platform/argoslower/pkg/ingress"
+)
+
+const gatewayGroup string = "gateway.networking.k8s.io"
+
+var (
+	HTTPRouteResource           = schema.GroupVersionResource{Group: gatewayGroup, Version: "v1", Resource: "httproutes"}
+	ReferenceGrantResource      = schema.GroupVersionResource{Group: gatewayGroup, Version: "v1beta1", Resource: "referencegrants"}
+	AuthorizationPolicyResource = schema.GroupVersionResource{Group: "security.istio.io", Version: "v1", Resource: "authorizationpolicies"}
+)
+
+// shortBearerRegex matches bearer tokens shorter than the 12 characters required for webhooks. This
+// regex is lax compared to the spec from https://tools.ietf.org/html/rfc6750#section-2.1 but it
+// aligns with the desired length requirements and implementation by argo events.
+const shortBearerRegex string = `^Bearer\s+\S{0,11}\s*$`
+
+// RenderHTTPRoute renders an HTTPRoute attached to the gateway routing the endpoints of an EventSource
+// at baseURL/es.Namespace/es.Name/endpoint to the port of the service assigned to the endpoint. The
+// exact endpoint path is rewritten to the endpoint and the namespace and name are stripped from sub
+// paths, so the trailing slash url reaches the endpoint as well.
+//
+// Requests carrying a bearer token too short for a webhook secret match a rule without backends and
+// are answered with a 500 by the gateway, where the istio VirtualService answers them with a 400. The
+// difference is intended: HTTPRoutes have no direct responses and AuthorizationPolicy conditions only
+// match header values by exact, prefix or suffix, so the token length can't be expressed as a policy.
+func RenderHTTPRoute(url string, gw, svc, es types.NamespacedName, endpoints map[string][]common.NamedPath) (*unstructured.Unstructured, error) {
+	pathPrefix := fmt.Sprintf("/%s/%s", es.Namespace, es.Name)
+
+	shortBearer := []interface{}{
+		map[string]interface{}{
+			"type":  "RegularExpression",
+			"name":  "authorization",
+			"value": shortBearerRegex,
+		},
+	}
+
+	rules := []interface{}{}
+	for _, pp := range sortedPaths(endpoints) {
+		port, err := strconv.ParseInt(pp.port, 10, 32)
+		if err != nil {
+			continue
+		}
+
+		path := pathPrefix + pp.path.Path
+		backendRefs := []interface{}{
+			map[string]interface{}{
+				"group":     "",
+				"kind":      "Service",
+				"name":      svc.Name,
+				"namespace": svc.Namespace,
+				"port":      port,
+				"weight":    int64(1),
+			},
+		}
+
+		rules = append(rules,
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path":    map[string]interface{}{"type": "Exact", "value": path},
+						"headers": shortBearer,
+					},
+					map[string]interface{}{
+						"path":    map[string]interface{}{"type": "PathPrefix", "value": path},
+						"headers": shortBearer,
+					},
+				},
+			},
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{"type": "Exact", "value": path},
+					},
+				},
+				"filters": []interface{}{
+					map[string]interface{}{
+						"type": "URLRewrite",
+						"urlRewrite": map[string]interface{}{
+							"path": map[string]interface{}{"type": "ReplaceFullPath", "replaceFullPath": pp.path.Path},
+						},
+					},
+				},
+				"backendRefs": backendRefs,
+			},
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{"type": "PathPrefix", "value": path},
+					},
+				},
+				"filters": []interface{}{
+					map[string]interface{}{
+						"type": "URLRewrite",
+						"urlRewrite": map[string]interface{}{
+							"path": map[string]interface{}{"type": "ReplacePrefixMatch", "replacePrefixMatch": pp.path.Path},
+						},
+					},
+				},
+				"backendRefs": backendRefs,
+			},
+		)
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no viable routes for EventSource %s and Service %s", es.String(), svc.String())
+	}
+
+	return newObject(HTTPRouteResource, "HTTPRoute", gw.Namespace, es, map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{
+				"group":     gatewayGroup,
+				"kind":      "Gateway",
+				"namespace": gw.Namespace,
+				"name":      gw.Name,
+			},
+		},
+		"hostnames": []interface{}{url},
+		"rules":     rules,
+	})
+}
+
+// RenderAuthorizationPolicy renders an istio AuthorizationPolicy attached to the gateway through its
+// targetRefs. The policy denies requests to the paths of each known source from outside the CIDRs of
+// the source.
+func RenderAuthorizationPolicy(url string, gw, es types.NamespacedName, inCIDRs map[string][]string, endpoints map[string][]common.NamedPath) (*unstructured.Unstructured, error) {
+	pathPrefix := fmt.Sprintf("/%s/%s", es.Namespace, es.Name)
+
+	paths := map[string][]interface{}{}
+	for _, port := range sortedPorts(endpoints) {
+		for _, path := range endpoints[port] {
+			paths[path.Source] = append(paths[path.Source], pathPrefix+path.Path, pathPrefix+path.Path+"/*")
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("eventSource %s has no valid paths for its service configuration", es.String())
+	}
+
+	sources := make([]string, 0, len(paths))
+	for source := range paths {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	rules := []interface{}{}
+	for _, s := range sources {
+		sourceCIDRs, ok := inCIDRs[s]
+		if !ok {
+			return nil, fmt.Errorf("eventSource %s has no source CIDRs for known source %s", es.String(), s)
+		}
+
+		cidrs := make([]interface{}, 0, len(sourceCIDRs))
+		for _, cidr := range sourceCIDRs {
+			cidrs = append(cidrs, cidr)
+		}
+
+		rules = append(rules, map[string]interface{}{
+			"from": []interface{}{
+				map[string]interface{}{
+					"source": map[string]interface{}{"notIpBlocks": cidrs},
+				},
+			},
+			"to": []interface{}{
+				map[string]interface{}{
+					"operation": map[string]interface{}{
+						"hosts": []interface{}{url, fmt.Sprintf("%s:*", url)},
+						"paths": paths[s],
+					},
+				},
+			},
+		})
+	}
+
+	return newObject(AuthorizationPolicyResource, "AuthorizationPolicy", gw.Namespace, es, map[string]interface{}{
+		"targetRefs": []interface{}{
+			map[string]interface{}{
+				"group": gatewayGroup,
+				"kind":  "Gateway",
+				"name":  gw.Name,
+			},
+		},
+		"action": "DENY",
+		"rules":  rules,
+	})
+}
+
+// RenderReferenceGrant renders the ReferenceGrant allowing HTTPRoutes of the gateway namespace to
+// reference the service of an EventSource in another namespace.
+func RenderReferenceGrant(gw, svc, es types.NamespacedName) (*unstructured.Unstructured, error) {
+	return newObject(ReferenceGrantResource, "ReferenceGrant", svc.Namespace, es, map[string]interface{}{
+		"from": []interface{}{
+			map[string]interface{}{
+				"group":     gatewayGroup,
+				"kind":      "HTTPRoute",
+				"namespace": gw.Namespace,
+			},
+		},
+		"to": []interface{}{
+			map[string]interface{}{
+				"group": "",
+				"kind":  "Service",
+				"name":  svc.Name,
+			},
+		},
+	})
+}
+
+// newObject returns an object of the EventSource labeled for the EventSource and annotated with the
+// desired state hash of its spec.
+func newObject(gvr schema.GroupVersionResource, kind, namespace string, es types.NamespacedName, spec map[string]interface{}) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(gvr.GroupVersion().WithKind(kind))
+	obj.SetName(common.ResourceName(es))
+	obj.SetNamespace(namespace)
+	obj.SetLabels(common.EventSourceLabels(es))
+
+	hash, err := desiredStateHash(obj)
+	if err != nil {
+		return nil, err
+	}
+	obj.SetAnnotations(map[string]string{common.DesiredStateAnnotationKey: hash})
+
+	return obj, nil
+}
+
+// desiredStateHash returns a hash of the spec and the EventSource labels of an object.
+func desiredStateHash(obj *unstructured.Unstructured) (string, error) {
+	encoded, err := json.Marshal(obj.Object["spec"])
+	if err != nil {
+		return "", err
+	}
+
+	labels := obj.GetLabels()
+	h := sha256.New()
+	h.Write(encoded)
+	for _, key := range []string{common.EventSourceNameString, common.EventSourceNamespaceString} {
+		fmt.Fprintf(h, "\n%s=%s", key, labels[key])
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// upToDate reports whether a live object was written with the desired hash and has not drifted from
+// it since.
+func upToDate(desired, live *unstructured.Unstructured) bool {
+	hash := desired.GetAnnotations()[common.DesiredStateAnnotationKey]
+	if hash == "" || live.GetAnnotations()[common.DesiredStateAnnotationKey] != hash {
+		return false
+	}
+
+	liveHash, err := desiredStateHash(live)
+	return err == nil && liveHash == hash
+}
+
+type portPath struct {
+	port string
+	path common.NamedPath
+}
+
+// sortedPaths returns the paths of an endpoint mapping in a stable order so rendered resources don't
+// change between reconciliations. Gateway API orders matches by precedence on its own.
+func sortedPaths(endpoints map[string][]common.NamedPath) []portPath {
+	out := []portPath{}
+	for _, port := range sortedPorts(endpoints) {
+		for _, path := range endpoints[port] {
+			out = append(out, portPath{port: port, path: path})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].path.Path < out[j].path.Path
+	})
+
+	return out
+}
+
+// sortedPorts returns the ports of an endpoint mapping in a stable order.
+func sortedPorts(endpoints map[string][]common.NamedPath) []string {
+	ports := make([]string, 0, len(endpoints))
+	for port := range endpoints {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	return ports
+}
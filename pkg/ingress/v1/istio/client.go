@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"sort"
 	"strconv"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -20,6 +21,8 @@ import (
 	netapplyv1beta1 "istio.io/client-go/pkg/applyconfiguration/networking/v1beta1"
 	secapplyv1beta1 "istio.io/client-go/pkg/applyconfiguration/security/v1beta1"
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
+	netlisters "istio.io/client-go/pkg/listers/networking/v1beta1"
+	seclisters "istio.io/client-go/pkg/listers/security/v1beta1"
 
 	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
 	common "github.com/kanopy-platform/argoslower/pkg/ingress"
@@ -30,6 +33,8 @@ import (
 type IstioClient struct {
 	client          istioclient.Interface
 	gatewaySelector map[string]string
+	vsLister        netlisters.VirtualServiceLister
+	apLister        seclisters.AuthorizationPolicyLister
 }
 
 func NewClient(cs istioclient.Interface, gs map[string]string) *IstioClient {
@@ -51,7 +56,7 @@ func (i *IstioClient) Remove(ctx context.Context, config *v1.EventSourceIngressC
 		return perrs.NewUnretryableError(fmt.Errorf("empty namespaced name for event source"))
 	}
 
-	selector := fmt.Sprintf("%s=%s,%s=%s", common.EventSourceNameString, config.Eventsource.Name, common.EventSourceNamespaceString, config.Eventsource.Namespace)
+	selector := common.LabelSelector(config.Eventsource)
 
 	listOpts := metav1.ListOptions{
 		LabelSelector: selector,
@@ -60,25 +65,59 @@ func (i *IstioClient) Remove(ctx context.Context, config *v1.EventSourceIngressC
 	apErr := sec.AuthorizationPolicies(config.AdminNamespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOpts)
 	vsErr := net.VirtualServices(config.Gateway.Namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOpts)
 
-	if apErr == nil && vsErr == nil {
-		return nil
-	}
-
-	var errString string
+	var errs error
 	if apErr != nil && !k8serrors.IsNotFound(apErr) {
-		log.V(5).Info("Istio api communication failure: %s", apErr.Error())
-		errString = fmt.Sprintf("%s%s", config.Eventsource.String(), apErr.Error())
+		log.V(5).Info("Istio api communication failure", "eventsource", config.Eventsource.String(), "resource", "authorizationpolicies", "error", apErr.Error())
+		errs = errors.Join(errs, fmt.Errorf("authorizationpolicies: %w", apErr))
 	}
 	if vsErr != nil && !k8serrors.IsNotFound(vsErr) {
-		log.V(5).Info("Istio api communication failure: %s", apErr.Error())
-		errString = fmt.Sprintf("%s%s", config.Eventsource.String(), vsErr.Error())
+		log.V(5).Info("Istio api communication failure", "eventsource", config.Eventsource.String(), "resource", "virtualservices", "error", vsErr.Error())
+		errs = errors.Join(errs, fmt.Errorf("virtualservices: %w", vsErr))
 	}
 
-	if errString == "" {
-		return nil
+	if errs != nil {
+		return perrs.NewRetryableError(fmt.Errorf("deletion errors for selector %s: %w", selector, errs))
 	}
 
-	return perrs.NewRetryableError(fmt.Errorf("deletion errors for selector %s: %s", selector, errString))
+	return nil
+}
+
+// ListEventSources returns the EventSources labeled on the VirtualServices of the gateway namespace and
+// the AuthorizationPolicies of the admin namespace.
+func (i *IstioClient) ListEventSources(ctx context.Context, config *v1.EventSourceIngressConfig) ([]types.NamespacedName, error) {
+	if config == nil || config.AdminNamespace == "" || config.Gateway.Namespace == "" {
+		return nil, perrs.NewUnretryableError(fmt.Errorf("empty admin or gateway namespace"))
+	}
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: common.GeneratedSelector,
+	}
+
+	vsl, err := i.client.NetworkingV1beta1().VirtualServices(config.Gateway.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, perrs.NewRetryableError(err)
+	}
+
+	apl, err := i.client.SecurityV1beta1().AuthorizationPolicies(config.AdminNamespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, perrs.NewRetryableError(err)
+	}
+
+	found := map[types.NamespacedName]bool{}
+	for _, vs := range vsl.Items {
+		found[common.EventSourceOf(vs.Labels)] = true
+	}
+	for _, ap := range apl.Items {
+		found[common.EventSourceOf(ap.Labels)] = true
+	}
+
+	out := make([]types.NamespacedName, 0, len(found))
+	for nsn := range found {
+		out = append(out, nsn)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].String() < out[b].String() })
+
+	return out, nil
 }
 
 // UpsertFromConfig creates or updates Virtual Serivces associated with an IstioConfig
@@ -93,6 +132,12 @@ func (i *IstioClient) upsertFromConfig(config *IstioConfig) (*isnetv1beta1.Virtu
 	}
 
 	vs := config.GetVirtualService()
+	vsHash, err := desiredStateHash(&vs.Spec, vs.Labels)
+	if err != nil {
+		return nil, nil, perrs.NewUnretryableError(err)
+	}
+	vs.Annotations = map[string]string{common.DesiredStateAnnotationKey: vsHash}
+
 	vsapply := netapplyv1beta1.VirtualService(vs.Name, vs.Namespace).
 		WithLabels(vs.Labels).
 		WithAnnotations(vs.Annotations)
@@ -101,6 +146,12 @@ func (i *IstioClient) upsertFromConfig(config *IstioConfig) (*isnetv1beta1.Virtu
 	vsapply.Spec = &vs.Spec
 
 	ap := config.GetAuthorizationPolicy()
+	apHash, err := desiredStateHash(&ap.Spec, ap.Labels)
+	if err != nil {
+		return nil, nil, perrs.NewUnretryableError(err)
+	}
+	ap.Annotations = map[string]string{common.DesiredStateAnnotationKey: apHash}
+
 	apapply := secapplyv1beta1.AuthorizationPolicy(ap.Name, ap.Namespace).
 		WithLabels(ap.Labels).
 		WithAnnotations(ap.Annotations)
@@ -112,17 +163,65 @@ func (i *IstioClient) upsertFromConfig(config *IstioConfig) (*isnetv1beta1.Virtu
 		FieldManager: "argoslower",
 	}
 
-	vso, err := net.VirtualServices(vs.Namespace).Apply(context.TODO(), vsapply, applyOpts)
-	if err != nil {
-		return vso, nil, err
+	// Applying with force would take over resources of another EventSource with the same name
+	vso, err := i.getVirtualService(context.TODO(), vs.Namespace, vs.Name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, nil, perrs.NewRetryableError(err)
+	}
+	vsExists := err == nil
+	if vsExists && !common.SameEventSource(vso.Labels, vs.Labels) {
+		return nil, nil, perrs.NewUnretryableError(fmt.Errorf("virtualservice %s/%s belongs to another eventsource", vs.Namespace, vs.Name))
+	}
+
+	apo, err := i.getAuthorizationPolicy(context.TODO(), ap.Namespace, ap.Name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, nil, perrs.NewRetryableError(err)
+	}
+	apExists := err == nil
+	if apExists && !common.SameEventSource(apo.Labels, ap.Labels) {
+		return nil, nil, perrs.NewUnretryableError(fmt.Errorf("authorizationpolicy %s/%s belongs to another eventsource", ap.Namespace, ap.Name))
+	}
+
+	// Only resources whose desired state changed or whose live state drifted are written
+	vsCurrent := vsExists && upToDate(vsHash, vso.Annotations, vso.Labels, &vso.Spec)
+	if !vsCurrent {
+		vso, err = net.VirtualServices(vs.Namespace).Apply(context.TODO(), vsapply, applyOpts)
+		if err != nil {
+			return vso, nil, err
+		}
 	}
 
-	apo, err := sec.AuthorizationPolicies(ap.Namespace).Apply(context.TODO(), apapply, applyOpts)
+	apCurrent := apExists && upToDate(apHash, apo.Annotations, apo.Labels, &apo.Spec)
+	if !apCurrent {
+		apo, err = sec.AuthorizationPolicies(ap.Namespace).Apply(context.TODO(), apapply, applyOpts)
+		if err != nil {
+			return vso, apo, err
+		}
+	}
+
+	stale, err := i.hasStale(vs, ap)
 	if err != nil {
-		return vso, apo, err
+		return vso, apo, perrs.NewRetryableError(fmt.Errorf("failed to list stale resources of %s: %w", vs.Name, err))
+	}
+	if !stale {
+		return vso, apo, nil
 	}
 
-	return vso, apo, err
+	// Resources of the EventSource with other names were rendered by earlier naming schemes
+	staleOpts := metav1.ListOptions{
+		LabelSelector: common.LabelSelector(common.EventSourceOf(vs.Labels)),
+		FieldSelector: fmt.Sprintf("metadata.name!=%s", vs.Name),
+	}
+	if err := net.VirtualServices(vs.Namespace).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, staleOpts); err != nil && !k8serrors.IsNotFound(err) {
+		return vso, apo, perrs.NewRetryableError(fmt.Errorf("failed to delete stale virtualservices of %s: %w", vs.Name, err))
+	}
+
+	staleOpts.FieldSelector = fmt.Sprintf("metadata.name!=%s", ap.Name)
+	if err := sec.AuthorizationPolicies(ap.Namespace).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, staleOpts); err != nil && !k8serrors.IsNotFound(err) {
+		return vso, apo, perrs.NewRetryableError(fmt.Errorf("failed to delete stale authorizationpolicies of %s: %w", ap.Name, err))
+	}
+
+	return vso, apo, nil
 }
 
 func (i *IstioClient) Configure(ctx context.Context, config *v1.EventSourceIngressConfig) ([]types.NamespacedName, error) {
@@ -133,16 +232,24 @@ func (i *IstioClient) Configure(ctx context.Context, config *v1.EventSourceIngre
 		return out, perrs.NewUnretryableError(fmt.Errorf("nil config"))
 	}
 
-	cidrs, err := config.IPGetter.GetIPs()
-	if err != nil {
-		log.V(5).Info(fmt.Sprintf("Failed to source IPs for %s: %s", config.Eventsource.String(), err.Error()))
-		return out, perrs.NewRetryableError(err)
-	}
+	cidrs := map[string][]string{}
+	for source, getter := range config.IPGetters {
+		if getter == nil {
+			return out, perrs.NewUnretryableError(fmt.Errorf("no IPGetter configured for source %s of %s", source, config.Eventsource.String()))
+		}
+
+		ips, err := getter.GetIPs()
+		if err != nil {
+			log.V(5).Info(fmt.Sprintf("Failed to source IPs for %s from %s: %s", config.Eventsource.String(), source, err.Error()))
+			return out, perrs.NewRetryableError(err)
+		}
 
-	if len(cidrs) == 0 {
-		e := fmt.Errorf("failed to source IPs for %s", config.Eventsource.String())
-		log.V(1).Info(e.Error())
-		return out, perrs.NewRetryableError(e)
+		if len(ips) == 0 {
+			e := fmt.Errorf("failed to source IPs for %s from %s", config.Eventsource.String(), source)
+			log.V(1).Info(e.Error())
+			return out, perrs.NewRetryableError(e)
+		}
+		cidrs[source] = ips
 	}
 
 	c := NewIstioConfig()
@@ -202,10 +309,11 @@ func (ic *IstioConfig) IsConfigured() bool {
 
 // ConfigureVS populates the IstioConfig.vs field with a VirtualService associated with the IC.
 // The VS is associated with the gateway assigned to the IstioConfig and maps paths onto the
-// base url in the format baseURL/es.Namespace/es.Name/endpoint/ as a prefix match.
+// base url in the format baseURL/es.Namespace/es.Name/endpoint/ as a prefix match and
+// baseURL/es.Namespace/es.Name/endpoint as an exact match.
 // The virtual service targets the fully qualified internal service host name on the port assigned
-// to the endpoint in the port mapping.
-func (ic *IstioConfig) ConfigureVS(url string, gw, svc, es types.NamespacedName, endpoints map[string]common.NamedPath) error {
+// to the endpoint in the port mapping. Every endpoint sharing a port receives its own routes.
+func (ic *IstioConfig) ConfigureVS(url string, gw, svc, es types.NamespacedName, endpoints map[string][]common.NamedPath) error {
 	host := url
 	pathPrefix := fmt.Sprintf("/%s/%s", es.Namespace, es.Name)
 	svcHost := fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace)
@@ -216,7 +324,7 @@ func (ic *IstioConfig) ConfigureVS(url string, gw, svc, es types.NamespacedName,
 			Gateways: []string{gw.String()},
 		},
 	}
-	vs.Name = fmt.Sprintf("%s-%s", es.Namespace, es.Name)
+	vs.Name = common.ResourceName(es)
 	vs.Namespace = gw.Namespace
 
 	vs.Labels = map[string]string{
@@ -224,16 +332,16 @@ func (ic *IstioConfig) ConfigureVS(url string, gw, svc, es types.NamespacedName,
 		common.EventSourceNamespaceString: es.Namespace,
 	}
 
-	routes := make([]*netv1beta1.HTTPRoute, len(endpoints)*2)
-	index := 0
-	for port, endpoint := range endpoints {
-		uport64, err := strconv.ParseUint(port, 10, 32)
+	routes := []*netv1beta1.HTTPRoute{}
+	for _, pp := range sortedPaths(endpoints) {
+		uport64, err := strconv.ParseUint(pp.port, 10, 32)
 		if err != nil {
 			continue
 		}
 		uport := uint32(uport64)
 
-		routes[index] = &netv1beta1.HTTPRoute{
+		endpoint := pp.path
+		routes = append(routes, &netv1beta1.HTTPRoute{
 			Name: endpoint.Name,
 			DirectResponse: &netv1beta1.HTTPDirectResponse{
 				Status: 400,
@@ -250,22 +358,47 @@ func (ic *IstioConfig) ConfigureVS(url string, gw, svc, es types.NamespacedName,
 							Prefix: fmt.Sprintf("%s%s/", pathPrefix, endpoint.Path),
 						},
 					},
-					Headers: map[string]*netv1beta1.StringMatch{
-						"authorization": &netv1beta1.StringMatch{
-							MatchType: &netv1beta1.StringMatch_Regex{
-								// This regex is lax compared to the spec from
-								// https://tools.ietf.org/html/rfc6750#section-2.1
-								// but it aligns with the desired length requirements
-								// and implementation by argo events
-								Regex: `^Bearer\s+\S{0,11}\s*$`,
-							},
+					Headers: shortBearerHeaders(),
+				},
+				&netv1beta1.HTTPMatchRequest{
+					Uri: &netv1beta1.StringMatch{
+						MatchType: &netv1beta1.StringMatch_Exact{
+							Exact: fmt.Sprintf("%s%s", pathPrefix, endpoint.Path),
 						},
 					},
+					Headers: shortBearerHeaders(),
 				},
 			},
-		}
-		index++
-		routes[index] = &netv1beta1.HTTPRoute{
+		})
+
+		// argo-events registers github webhooks at the url followed by the endpoint without a
+		// trailing slash, the exact path is routed to the endpoint of the EventSource
+		routes = append(routes, &netv1beta1.HTTPRoute{
+			Name: endpoint.Name,
+			Route: []*netv1beta1.HTTPRouteDestination{
+				&netv1beta1.HTTPRouteDestination{
+					Destination: &netv1beta1.Destination{
+						Host: svcHost,
+						Port: &netv1beta1.PortSelector{
+							Number: uport,
+						},
+					},
+				},
+			},
+			Match: []*netv1beta1.HTTPMatchRequest{
+				&netv1beta1.HTTPMatchRequest{
+					Uri: &netv1beta1.StringMatch{
+						MatchType: &netv1beta1.StringMatch_Exact{
+							Exact: fmt.Sprintf("%s%s", pathPrefix, endpoint.Path),
+						},
+					},
+				},
+			},
+			Rewrite: &netv1beta1.HTTPRewrite{Uri: endpoint.Path},
+		})
+
+		// sub paths and the trailing slash keep the endpoint so both forms reach the same upstream path
+		routes = append(routes, &netv1beta1.HTTPRoute{
 			Name: endpoint.Name,
 			Route: []*netv1beta1.HTTPRouteDestination{
 				&netv1beta1.HTTPRouteDestination{
@@ -286,9 +419,8 @@ func (ic *IstioConfig) ConfigureVS(url string, gw, svc, es types.NamespacedName,
 					},
 				},
 			},
-			Rewrite: &netv1beta1.HTTPRewrite{Uri: "/"},
-		}
-		index++
+			Rewrite: &netv1beta1.HTTPRewrite{Uri: endpoint.Path + "/"},
+		})
 	}
 
 	if len(routes) == 0 {
@@ -301,13 +433,10 @@ func (ic *IstioConfig) ConfigureVS(url string, gw, svc, es types.NamespacedName,
 }
 
 // ConfigureAP configures the IstioConfig.ap field with an AuthorizationPolicy base on the inputs.
-// The AP will contain a single rule that contains the full IP CIDR list and all paths from the
-// endpoint mapping with a glob match. The AP will match the baseURL and baseURL:* hostnames
-// per istio host match best practice.
-func (ic *IstioConfig) ConfigureAP(adminns, url string, nsn types.NamespacedName, inCIDRs []string, endpoints map[string]common.NamedPath, gws map[string]string) error {
-
-	cidrs := make([]string, len(inCIDRs))
-	copy(cidrs, inCIDRs)
+// The AP will contain a rule per known source that contains the full IP CIDR list of the source and
+// all paths from the endpoint mapping assigned to the source with an exact and a glob match. The AP will match the
+// baseURL and baseURL:* hostnames per istio host match best practice.
+func (ic *IstioConfig) ConfigureAP(adminns, url string, nsn types.NamespacedName, inCIDRs map[string][]string, endpoints map[string][]common.NamedPath, gws map[string]string) error {
 
 	pathPrefix := fmt.Sprintf("/%s/%s", nsn.Namespace, nsn.Name)
 	matcher := maps.Clone(gws)
@@ -320,51 +449,117 @@ func (ic *IstioConfig) ConfigureAP(adminns, url string, nsn types.NamespacedName
 			Action: secv1beta1.AuthorizationPolicy_DENY,
 		},
 	}
-	ap.Name = fmt.Sprintf("%s-%s", nsn.Namespace, nsn.Name)
+	ap.Name = common.ResourceName(nsn)
 	ap.Namespace = adminns
 	ap.Labels = map[string]string{
 		"eventsource-name":      nsn.Name,
 		"eventsource-namespace": nsn.Namespace,
 	}
 
-	paths := make([]string, len(endpoints))
-	index := 0
-	for _, path := range endpoints {
-		paths[index] = pathPrefix + path.Path + "/*"
-		index++
+	paths := map[string][]string{}
+	for _, port := range sortedPorts(endpoints) {
+		for _, path := range endpoints[port] {
+			paths[path.Source] = append(paths[path.Source], pathPrefix+path.Path, pathPrefix+path.Path+"/*")
+		}
 	}
 	if len(paths) == 0 {
 		return fmt.Errorf("eventSource %s has no valid paths for its service configuration", nsn.String())
 	}
 
-	source := &secv1beta1.Source{}
-	if ap.Spec.Action == secv1beta1.AuthorizationPolicy_DENY {
-		source.NotIpBlocks = cidrs
-	} else {
-		source.IpBlocks = cidrs
+	sources := make([]string, 0, len(paths))
+	for source := range paths {
+		sources = append(sources, source)
 	}
+	sort.Strings(sources)
 
-	rule := &secv1beta1.Rule{
-		From: []*secv1beta1.Rule_From{
-			&secv1beta1.Rule_From{
-				Source: source,
+	for _, s := range sources {
+		sourceCIDRs, ok := inCIDRs[s]
+		if !ok {
+			return fmt.Errorf("eventSource %s has no source CIDRs for known source %s", nsn.String(), s)
+		}
+		cidrs := make([]string, len(sourceCIDRs))
+		copy(cidrs, sourceCIDRs)
+
+		source := &secv1beta1.Source{}
+		if ap.Spec.Action == secv1beta1.AuthorizationPolicy_DENY {
+			source.NotIpBlocks = cidrs
+		} else {
+			source.IpBlocks = cidrs
+		}
+
+		rule := &secv1beta1.Rule{
+			From: []*secv1beta1.Rule_From{
+				&secv1beta1.Rule_From{
+					Source: source,
+				},
 			},
-		},
-		To: []*secv1beta1.Rule_To{
-			&secv1beta1.Rule_To{
-				Operation: &secv1beta1.Operation{
-					Hosts: []string{
-						url,
-						fmt.Sprintf("%s:*", url),
+			To: []*secv1beta1.Rule_To{
+				&secv1beta1.Rule_To{
+					Operation: &secv1beta1.Operation{
+						Hosts: []string{
+							url,
+							fmt.Sprintf("%s:*", url),
+						},
+						Paths: paths[s],
 					},
-					Paths: paths,
 				},
 			},
-		},
-	}
+		}
 
-	ap.Spec.Rules = append(ap.Spec.Rules, rule)
+		ap.Spec.Rules = append(ap.Spec.Rules, rule)
+	}
 
 	ic.ap = &ap
 	return nil
 }
+
+// shortBearerHeaders matches bearer tokens shorter than the 12 characters required for webhooks.
+func shortBearerHeaders() map[string]*netv1beta1.StringMatch {
+	return map[string]*netv1beta1.StringMatch{
+		"authorization": &netv1beta1.StringMatch{
+			MatchType: &netv1beta1.StringMatch_Regex{
+				// This regex is lax compared to the spec from
+				// https://tools.ietf.org/html/rfc6750#section-2.1
+				// but it aligns with the desired length requirements
+				// and implementation by argo events
+				Regex: `^Bearer\s+\S{0,11}\s*$`,
+			},
+		},
+	}
+}
+
+type portPath struct {
+	port string
+	path common.NamedPath
+}
+
+// sortedPaths returns the paths of an endpoint mapping ordered by descending path length so longer
+// prefix matches are evaluated before shorter paths shadowing them, i.e. /a/b before /a.
+func sortedPaths(endpoints map[string][]common.NamedPath) []portPath {
+	out := []portPath{}
+	for _, port := range sortedPorts(endpoints) {
+		for _, path := range endpoints[port] {
+			out = append(out, portPath{port: port, path: path})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if len(out[i].path.Path) != len(out[j].path.Path) {
+			return len(out[i].path.Path) > len(out[j].path.Path)
+		}
+		return out[i].path.Path < out[j].path.Path
+	})
+
+	return out
+}
+
+// sortedPorts returns the ports of an endpoint mapping in a stable order so rendered
+// resources don't change between reconciliations
+func sortedPorts(endpoints map[string][]common.NamedPath) []string {
+	ports := make([]string, 0, len(endpoints))
+	for port := range endpoints {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	return ports
+}
@@ -2,6 +2,7 @@ package istio
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
@@ -20,7 +21,7 @@ func TestConfigureVS(t *testing.T) {
 		gateway   types.NamespacedName
 		svc       types.NamespacedName
 		es        types.NamespacedName
-		endpoints map[string]common.NamedPath
+		endpoints map[string][]common.NamedPath
 		err       bool
 	}{
 		{
@@ -47,14 +48,46 @@ func TestConfigureVS(t *testing.T) {
 				Name:      "eventsource",
 				Namespace: "destination",
 			},
-			endpoints: map[string]common.NamedPath{
-				"12345": common.NamedPath{
-					Name: "thingOne",
-					Path: "/t1",
+			endpoints: map[string][]common.NamedPath{
+				"12345": []common.NamedPath{
+					common.NamedPath{
+						Name: "thingOne",
+						Path: "/t1",
+					},
 				},
-				"54321": common.NamedPath{
-					Name: "thingTwo",
-					Path: "/t2",
+				"54321": []common.NamedPath{
+					common.NamedPath{
+						Name: "thingTwo",
+						Path: "/t2",
+					},
+				},
+			},
+		},
+		{
+			name:    "shared port",
+			baseURL: "gateway.example.com",
+			gateway: types.NamespacedName{
+				Name:      "gateway",
+				Namespace: "routing",
+			},
+			svc: types.NamespacedName{
+				Name:      "upstreamservice",
+				Namespace: "destination",
+			},
+			es: types.NamespacedName{
+				Name:      "eventsource",
+				Namespace: "destination",
+			},
+			endpoints: map[string][]common.NamedPath{
+				"12345": []common.NamedPath{
+					common.NamedPath{
+						Name: "thingOne",
+						Path: "/t1",
+					},
+					common.NamedPath{
+						Name: "thingTwo",
+						Path: "/t2",
+					},
 				},
 			},
 		},
@@ -71,10 +104,11 @@ func TestConfigureVS(t *testing.T) {
 		vs := ic.GetVirtualService()
 		require.NotNil(t, vs, test.name)
 
+		assert.Equal(t, common.ResourceName(test.es), vs.Name, test.name)
 		assert.Equal(t, test.es.Name, vs.Labels[common.EventSourceNameString], test)
 		assert.Equal(t, test.es.Namespace, vs.Labels[common.EventSourceNamespaceString], test)
 
-		var directResponseCount int
+		var directResponseCount, exactCount int
 		for _, route := range vs.Spec.Http {
 			if route.DirectResponse != nil {
 				urlPrefix := route.Match[0].Uri.GetPrefix()
@@ -83,20 +117,38 @@ func TestConfigureVS(t *testing.T) {
 				headerMatch := route.Match[0].Headers["authorization"].GetRegex()
 				assert.NotNil(t, headerMatch, test.name)
 				assert.Equal(t, `^Bearer\s+\S{0,11}\s*$`, headerMatch, test.name)
+				require.Len(t, route.Match, 2, test.name)
+				assert.Equal(t, strings.TrimSuffix(urlPrefix, "/"), route.Match[1].Uri.GetExact(), test.name)
+				assert.Equal(t, headerMatch, route.Match[1].Headers["authorization"].GetRegex(), test.name)
 				directResponseCount++
 				continue
 			}
 
+			// exact routes rewrite the registered github webhook path to the endpoint
+			if exact := route.Match[0].Uri.GetExact(); exact != "" {
+				assert.Equal(t, fmt.Sprintf("/%s/%s%s", test.es.Namespace, test.es.Name, route.Rewrite.Uri), exact, test.name)
+				exactCount++
+				continue
+			}
+
 			// destination should be the fully qualified internal service name
 			assert.Equal(t, fmt.Sprintf("%s.%s.svc.cluster.local", test.svc.Name, test.svc.Namespace), route.Route[0].Destination.Host, test.name)
 
-			// detination port should 1. appear in the endpoint map 2. match the /namespace/eventsourcename/endpoint/ prefix match for the endpoint
-			ep, ok := test.endpoints[fmt.Sprintf("%d", route.Route[0].Destination.Port.Number)]
+			// detination port should 1. appear in the endpoint map 2. match the /namespace/eventsourcename/endpoint/ prefix match for an endpoint on the port
+			eps, ok := test.endpoints[fmt.Sprintf("%d", route.Route[0].Destination.Port.Number)]
 			assert.True(t, ok, test.name)
 			urlPrefix := route.Match[0].Uri.GetPrefix()
-			assert.Equal(t, fmt.Sprintf("/%s/%s%s/", test.es.Namespace, test.es.Name, ep.Path), urlPrefix, test.name)
+			prefixes := []string{}
+			for _, ep := range eps {
+				prefixes = append(prefixes, fmt.Sprintf("/%s/%s%s/", test.es.Namespace, test.es.Name, ep.Path))
+			}
+			assert.Contains(t, prefixes, urlPrefix, test.name)
+
+			// the trailing slash url /namespace/eventsourcename/endpoint/ is rewritten to /endpoint/
+			assert.Equal(t, strings.TrimPrefix(urlPrefix, fmt.Sprintf("/%s/%s", test.es.Namespace, test.es.Name)), route.Rewrite.Uri, test.name)
 		}
-		assert.Equal(t, directResponseCount, len(test.endpoints), test.name)
+		assert.Equal(t, directResponseCount, countPaths(test.endpoints), test.name)
+		assert.Equal(t, exactCount, countPaths(test.endpoints), test.name)
 	}
 }
 
@@ -109,8 +161,8 @@ func TestConfigureAP(t *testing.T) {
 		baseURL   string
 		gws       map[string]string
 		es        types.NamespacedName
-		cidrs     []string
-		endpoints map[string]common.NamedPath
+		cidrs     map[string][]string
+		endpoints map[string][]common.NamedPath
 		err       bool
 	}{
 		{
@@ -133,17 +185,91 @@ func TestConfigureAP(t *testing.T) {
 				Name:      "eventsource",
 				Namespace: "destination",
 			},
-			cidrs: []string{
-				"1.2.3.4",
-				"2.3.4.5/24",
+			cidrs: map[string][]string{
+				"github": []string{
+					"1.2.3.4",
+					"2.3.4.5/24",
+				},
 			},
-			endpoints: map[string]common.NamedPath{
-				"12345": common.NamedPath{
-					Name: "thing",
-					Path: "/thingtarget",
+			endpoints: map[string][]common.NamedPath{
+				"12345": []common.NamedPath{
+					common.NamedPath{
+						Name:   "thing",
+						Path:   "/thingtarget",
+						Source: "github",
+					},
+					common.NamedPath{
+						Name:   "other",
+						Path:   "/othertarget",
+						Source: "github",
+					},
+				},
+				"54321": []common.NamedPath{
+					common.NamedPath{
+						Name:   "thingTwo",
+						Path:   "/thingtwo",
+						Source: "github",
+					},
 				},
 			},
 		},
+		{
+			name:    "multiple sources",
+			adminNS: "routing",
+			baseURL: "gateway.example.com",
+			gws: map[string]string{
+				"istio": "example-ingressgateway",
+			},
+			es: types.NamespacedName{
+				Name:      "eventsource",
+				Namespace: "destination",
+			},
+			cidrs: map[string][]string{
+				"github": []string{
+					"1.2.3.4/32",
+				},
+				"jira": []string{
+					"10.0.0.0/8",
+				},
+			},
+			endpoints: map[string][]common.NamedPath{
+				"12345": []common.NamedPath{
+					common.NamedPath{
+						Name:   "github",
+						Path:   "/github",
+						Source: "github",
+					},
+					common.NamedPath{
+						Name:   "jira",
+						Path:   "/jira",
+						Source: "jira",
+					},
+				},
+			},
+		},
+		{
+			name:    "missing source cidrs",
+			adminNS: "routing",
+			baseURL: "gateway.example.com",
+			gws: map[string]string{
+				"istio": "example-ingressgateway",
+			},
+			es: types.NamespacedName{
+				Name:      "eventsource",
+				Namespace: "destination",
+			},
+			cidrs: map[string][]string{},
+			endpoints: map[string][]common.NamedPath{
+				"12345": []common.NamedPath{
+					common.NamedPath{
+						Name:   "jira",
+						Path:   "/jira",
+						Source: "jira",
+					},
+				},
+			},
+			err: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -159,15 +285,75 @@ func TestConfigureAP(t *testing.T) {
 		require.NotNil(t, ap, test.name)
 
 		assert.Equal(t, test.adminNS, ap.Namespace, test.name)
-		assert.Equal(t, test.cidrs, ap.Spec.Rules[0].From[0].Source.NotIpBlocks, test.name)
 
 		assert.Equal(t, test.es.Name, ap.Labels[common.EventSourceNameString], test)
 		assert.Equal(t, test.es.Namespace, ap.Labels[common.EventSourceNamespaceString], test)
 
-		assert.Equal(t, len(test.endpoints), len(ap.Spec.Rules[0].To[0].Operation.Paths), test.name)
-		for _, endpoint := range test.endpoints {
-			path := fmt.Sprintf("/%s/%s%s/*", test.es.Namespace, test.es.Name, endpoint.Path)
-			assert.Contains(t, ap.Spec.Rules[0].To[0].Operation.Paths, path, test.name)
+		// one rule per source, each rule restricts the paths of the source to the CIDRs of the source
+		assert.Equal(t, len(test.cidrs), len(ap.Spec.Rules), test.name)
+		paths := 0
+		for _, rule := range ap.Spec.Rules {
+			paths += len(rule.To[0].Operation.Paths)
+		}
+		assert.Equal(t, 2*countPaths(test.endpoints), paths, test.name)
+
+		for _, endpoints := range test.endpoints {
+			for _, endpoint := range endpoints {
+				path := fmt.Sprintf("/%s/%s%s/*", test.es.Namespace, test.es.Name, endpoint.Path)
+				exact := strings.TrimSuffix(path, "/*")
+				for _, rule := range ap.Spec.Rules {
+					if slices.Contains(rule.To[0].Operation.Paths, path) {
+						assert.Contains(t, rule.To[0].Operation.Paths, exact, test.name)
+					}
+				}
+				found := false
+				for _, rule := range ap.Spec.Rules {
+					for _, p := range rule.To[0].Operation.Paths {
+						if p != path {
+							continue
+						}
+						found = true
+						assert.Equal(t, test.cidrs[endpoint.Source], rule.From[0].Source.NotIpBlocks, test.name)
+					}
+				}
+				assert.True(t, found, test.name)
+			}
+		}
+	}
+}
+
+func countPaths(endpoints map[string][]common.NamedPath) int {
+	count := 0
+	for _, paths := range endpoints {
+		count += len(paths)
+	}
+	return count
+}
+
+func TestConfigureVSRouteOrder(t *testing.T) {
+	ic := NewIstioConfig()
+
+	es := types.NamespacedName{Name: "eventsource", Namespace: "destination"}
+	endpoints := map[string][]common.NamedPath{
+		"12000": []common.NamedPath{
+			common.NamedPath{Name: "short", Path: "/push"},
+		},
+		"13000": []common.NamedPath{
+			common.NamedPath{Name: "long", Path: "/push/nested"},
+		},
+	}
+
+	err := ic.ConfigureVS("gateway.example.com", types.NamespacedName{Name: "gateway", Namespace: "routing"}, types.NamespacedName{Name: "svc", Namespace: "destination"}, es, endpoints)
+	require.NoError(t, err)
+
+	ports := []uint32{}
+	for _, route := range ic.GetVirtualService().Spec.Http {
+		if route.DirectResponse != nil || len(route.Route) == 0 {
+			continue
 		}
+		ports = append(ports, route.Route[0].Destination.Port.Number)
 	}
+
+	// the longer path is routed before the shorter path it is nested in
+	assert.Equal(t, []uint32{13000, 13000, 12000, 12000}, ports)
 }
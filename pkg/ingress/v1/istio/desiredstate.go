@@ -0,0 +1,105 @@
+package istio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	isnetv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	issecv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	netlisters "istio.io/client-go/pkg/listers/networking/v1beta1"
+	seclisters "istio.io/client-go/pkg/listers/security/v1beta1"
+
+	common "github.com/kanopy-platform/argoslower/pkg/ingress"
+)
+
+// SetListers configures informer caches for looking up the live VirtualServices and
+// AuthorizationPolicies before applying. The API server is queried without listers.
+func (i *IstioClient) SetListers(vsl netlisters.VirtualServiceLister, apl seclisters.AuthorizationPolicyLister) {
+	i.vsLister = vsl
+	i.apLister = apl
+}
+
+// desiredStateHash returns a hash of a rendered spec and the EventSource labels of a resource.
+func desiredStateHash(spec proto.Message, resourceLabels map[string]string) (string, error) {
+	encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(encoded)
+	for _, key := range []string{common.EventSourceNameString, common.EventSourceNamespaceString} {
+		fmt.Fprintf(h, "\n%s=%s", key, resourceLabels[key])
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// upToDate reports whether a live resource was written with the desired hash and has not drifted
+// from it since.
+func upToDate(desired string, annotations, resourceLabels map[string]string, spec proto.Message) bool {
+	if desired == "" || annotations[common.DesiredStateAnnotationKey] != desired {
+		return false
+	}
+
+	live, err := desiredStateHash(spec, resourceLabels)
+	return err == nil && live == desired
+}
+
+// getVirtualService returns the live VirtualService from the informer cache when configured.
+func (i *IstioClient) getVirtualService(ctx context.Context, namespace, name string) (*isnetv1beta1.VirtualService, error) {
+	if i.vsLister == nil {
+		return i.client.NetworkingV1beta1().VirtualServices(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	return i.vsLister.VirtualServices(namespace).Get(name)
+}
+
+// getAuthorizationPolicy returns the live AuthorizationPolicy from the informer cache when configured.
+func (i *IstioClient) getAuthorizationPolicy(ctx context.Context, namespace, name string) (*issecv1beta1.AuthorizationPolicy, error) {
+	if i.apLister == nil {
+		return i.client.SecurityV1beta1().AuthorizationPolicies(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	return i.apLister.AuthorizationPolicies(namespace).Get(name)
+}
+
+// hasStale reports whether resources of the EventSource with other names than the rendered ones
+// exist. Without listers the stale resources are always deleted.
+func (i *IstioClient) hasStale(vs *isnetv1beta1.VirtualService, ap *issecv1beta1.AuthorizationPolicy) (bool, error) {
+	if i.vsLister == nil || i.apLister == nil {
+		return true, nil
+	}
+
+	selector, err := labels.Parse(common.LabelSelector(common.EventSourceOf(vs.Labels)))
+	if err != nil {
+		return true, err
+	}
+
+	vsl, err := i.vsLister.VirtualServices(vs.Namespace).List(selector)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return true, err
+	}
+	for _, o := range vsl {
+		if o.Name != vs.Name {
+			return true, nil
+		}
+	}
+
+	apl, err := i.apLister.AuthorizationPolicies(ap.Namespace).List(selector)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return true, err
+	}
+	for _, o := range apl {
+		if o.Name != ap.Name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,89 @@
+package istio
+
+import (
+	"testing"
+
+	common "github.com/kanopy-platform/argoslower/pkg/ingress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestUpToDate(t *testing.T) {
+	es := types.NamespacedName{Namespace: "destination", Name: "eventsource"}
+	endpoints := map[string][]common.NamedPath{
+		"12345": []common.NamedPath{
+			common.NamedPath{Name: "github", Path: "/github", Source: "github"},
+		},
+	}
+
+	render := func() *IstioConfig {
+		ic := NewIstioConfig()
+		require.NoError(t, ic.ConfigureAP("routing", "gateway.example.com", es, map[string][]string{"github": []string{"1.2.3.4/32"}}, endpoints, map[string]string{"istio": "ingressgateway"}))
+		return ic
+	}
+
+	desired := render().GetAuthorizationPolicy()
+	hash, err := desiredStateHash(&desired.Spec, desired.Labels)
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+
+	again, err := desiredStateHash(&render().GetAuthorizationPolicy().Spec, desired.Labels)
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	tests := []struct {
+		name   string
+		mutate func(annotations, labels map[string]string, live *IstioConfig)
+		want   bool
+	}{
+		{
+			name:   "unchanged",
+			mutate: func(annotations, labels map[string]string, live *IstioConfig) {},
+			want:   true,
+		},
+		{
+			name: "missing annotation",
+			mutate: func(annotations, labels map[string]string, live *IstioConfig) {
+				delete(annotations, common.DesiredStateAnnotationKey)
+			},
+		},
+		{
+			name: "desired state changed",
+			mutate: func(annotations, labels map[string]string, live *IstioConfig) {
+				annotations[common.DesiredStateAnnotationKey] = "outdated"
+			},
+		},
+		{
+			name: "drifted spec",
+			mutate: func(annotations, labels map[string]string, live *IstioConfig) {
+				live.ap.Spec.Rules[0].From[0].Source.NotIpBlocks = []string{"0.0.0.0/0"}
+			},
+		},
+		{
+			name: "drifted label",
+			mutate: func(annotations, labels map[string]string, live *IstioConfig) {
+				labels[common.EventSourceNameString] = "other"
+			},
+		},
+		{
+			name: "unrelated label",
+			mutate: func(annotations, labels map[string]string, live *IstioConfig) {
+				labels["team"] = "platform"
+			},
+			want: true,
+		},
+	}
+
+	for _, test := range tests {
+		live := render()
+		annotations := map[string]string{common.DesiredStateAnnotationKey: hash}
+		labels := map[string]string{}
+		for k, v := range live.ap.Labels {
+			labels[k] = v
+		}
+
+		test.mutate(annotations, labels, live)
+		assert.Equal(t, test.want, upToDate(hash, annotations, labels, &live.ap.Spec), test.name)
+	}
+}
@@ -9,11 +9,12 @@ import (
 
 // EventSourceIngressConfig provides the information needed for rendering
 // ingress resources mapped to the service of an argo event source.
-// it is ingress provider agnostic.
+// it is ingress provider agnostic. IPGetters are keyed by the known source
+// assigned to each endpoint.
 type EventSourceIngressConfig struct {
-	IPGetter       IPGetter
+	IPGetters      map[string]IPGetter
 	Eventsource    types.NamespacedName
-	Endpoints      map[string]ingresscommon.NamedPath
+	Endpoints      map[string][]ingresscommon.NamedPath
 	AdminNamespace string
 	BaseURL        string
 	Gateway        types.NamespacedName
@@ -29,3 +30,15 @@ type IngressConfigurator interface {
 	Configure(ctx context.Context, config *EventSourceIngressConfig) ([]types.NamespacedName, error)
 	Remove(ctx context.Context, config *EventSourceIngressConfig) error
 }
+
+// IPWatcher is implemented by IPGetters notifying about changes of their CIDRs. notify is called after
+// every change until the context is done.
+type IPWatcher interface {
+	Watch(ctx context.Context, notify func())
+}
+
+// IngressLister is implemented by IngressConfigurators able to list the EventSources they generated
+// ingress resources for. The gateway and admin namespaces of the config locate the resources.
+type IngressLister interface {
+	ListEventSources(ctx context.Context, config *EventSourceIngressConfig) ([]types.NamespacedName, error)
+}
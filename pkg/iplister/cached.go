@@ -1,7 +1,9 @@
 package iplister
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 )
@@ -12,6 +14,8 @@ type CachedIPLister struct {
 	syncInterval time.Duration
 	lock         *sync.RWMutex
 	ips          []string
+	watchers     map[int]func()
+	nextWatcher  int
 }
 
 func NewCachedIPLister(lister *IPLister) *CachedIPLister {
@@ -19,6 +23,7 @@ func NewCachedIPLister(lister *IPLister) *CachedIPLister {
 		lister:       lister,
 		syncInterval: 5 * time.Minute,
 		lock:         &sync.RWMutex{},
+		watchers:     map[int]func(){},
 	}
 	_ = out.setIPs()
 	return out
@@ -55,14 +60,66 @@ func (i *CachedIPLister) getIPs() []string {
 
 func (i *CachedIPLister) setIPs() error {
 	i.lock.Lock()
-	defer i.lock.Unlock()
 	// always update the sync time to keep from overwhelming the reader target
 	i.lastSync = time.Now()
 	ipList, err := i.lister.GetIPs()
 	if err != nil {
+		i.lock.Unlock()
 		return err
 	}
 
+	changed := i.ips != nil && !sameCIDRs(i.ips, ipList)
 	i.ips = ipList
+
+	notify := make([]func(), 0, len(i.watchers))
+	for _, watcher := range i.watchers {
+		notify = append(notify, watcher)
+	}
+	i.lock.Unlock()
+
+	if changed {
+		for _, n := range notify {
+			n()
+		}
+	}
 	return nil
 }
+
+// Watch calls notify whenever a sync changes the cached ip list and keeps refreshing stale lists until
+// the context is done, instead of only when GetIPs is called.
+func (i *CachedIPLister) Watch(ctx context.Context, notify func()) {
+	i.lock.Lock()
+	id := i.nextWatcher
+	i.nextWatcher++
+	i.watchers[id] = notify
+	interval := i.syncInterval
+	i.lock.Unlock()
+
+	go func() {
+		// check more often than the sync interval so a refresh is at most a fraction of it late
+		ticker := time.NewTicker(interval / 5)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				i.lock.Lock()
+				delete(i.watchers, id)
+				i.lock.Unlock()
+				return
+			case <-ticker.C:
+				// errors retain the stale list and are surfaced by the next reconcile
+				_, _ = i.GetIPs()
+			}
+		}
+	}()
+}
+
+// sameCIDRs compares two ip lists regardless of their order.
+func sameCIDRs(a, b []string) bool {
+	a = slices.Clone(a)
+	b = slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
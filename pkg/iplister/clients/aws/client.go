@@ -0,0 +1,15 @@
+package aws
+
+import (
+	"github.com/kanopy-platform/argoslower/pkg/iplister"
+	"github.com/kanopy-platform/argoslower/pkg/iplister/decoder/aws"
+	"github.com/kanopy-platform/argoslower/pkg/iplister/reader/http"
+)
+
+const AWSURL string = "https://ip-ranges.amazonaws.com/ip-ranges.json"
+
+// New returns an IPLister for the published AWS ip ranges filtered by service and region.
+// Empty filters match all prefixes.
+func New(service, region string) *iplister.IPLister {
+	return iplister.New(http.New(AWSURL), aws.New(service, region))
+}
@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Structs for marshalling in data from https://ip-ranges.amazonaws.com/ip-ranges.json
+type (
+	ipv4Prefix struct {
+		IPPrefix string `json:"ip_prefix"`
+		Region   string `json:"region"`
+		Service  string `json:"service"`
+	}
+	ipv6Prefix struct {
+		IPv6Prefix string `json:"ipv6_prefix"`
+		Region     string `json:"region"`
+		Service    string `json:"service"`
+	}
+	ipRanges struct {
+		Prefixes     []ipv4Prefix `json:"prefixes"`
+		IPv6Prefixes []ipv6Prefix `json:"ipv6_prefixes"`
+	}
+)
+
+// AWS decodes the published AWS ip ranges. Prefixes are filtered by service
+// (i.e. AMAZON or EC2) and region (i.e. us-east-1) when they are set.
+type AWS struct {
+	service string
+	region  string
+}
+
+func New(service, region string) *AWS {
+	return &AWS{
+		service: service,
+		region:  region,
+	}
+}
+
+func (a *AWS) Decode(data io.ReadCloser) ([]string, error) {
+	var resp ipRanges
+	err := json.NewDecoder(data).Decode(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.extractCIDRs(a.service, a.region), nil
+}
+
+func (r *ipRanges) extractCIDRs(service, region string) []string {
+	res := []string{}
+	seen := map[string]bool{}
+
+	add := func(cidr, s, reg string) {
+		if service != "" && s != service {
+			return
+		}
+		if region != "" && reg != region {
+			return
+		}
+		// the same prefix is listed once per service it belongs to
+		if seen[cidr] {
+			return
+		}
+		seen[cidr] = true
+		res = append(res, cidr)
+	}
+
+	for _, p := range r.Prefixes {
+		add(p.IPPrefix, p.Service, p.Region)
+	}
+
+	for _, p := range r.IPv6Prefixes {
+		add(p.IPv6Prefix, p.Service, p.Region)
+	}
+
+	return res
+}
@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockReadCloser(b []byte) io.ReadCloser {
+	reader := bytes.NewReader(b)
+	return io.NopCloser(reader)
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	fakeResponse := ipRanges{
+		Prefixes: []ipv4Prefix{
+			{
+				IPPrefix: "1.2.3.0/24",
+				Region:   "us-east-1",
+				Service:  "AMAZON",
+			},
+			{
+				IPPrefix: "1.2.3.0/24",
+				Region:   "us-east-1",
+				Service:  "EC2",
+			},
+			{
+				IPPrefix: "2.3.4.0/24",
+				Region:   "eu-west-1",
+				Service:  "AMAZON",
+			},
+		},
+		IPv6Prefixes: []ipv6Prefix{
+			{
+				IPv6Prefix: "2600:1f00::/24",
+				Region:     "us-east-1",
+				Service:    "AMAZON",
+			},
+		},
+	}
+
+	fakeData, err := json.Marshal(&fakeResponse)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		service string
+		region  string
+		want    []string
+	}{
+		{
+			name: "unfiltered",
+			want: []string{"1.2.3.0/24", "2.3.4.0/24", "2600:1f00::/24"},
+		},
+		{
+			name:    "service",
+			service: "EC2",
+			want:    []string{"1.2.3.0/24"},
+		},
+		{
+			name:   "region",
+			region: "eu-west-1",
+			want:   []string{"2.3.4.0/24"},
+		},
+		{
+			name:    "service and region",
+			service: "AMAZON",
+			region:  "us-east-1",
+			want:    []string{"1.2.3.0/24", "2600:1f00::/24"},
+		},
+		{
+			name:    "no match",
+			service: "S3",
+			want:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		a := New(test.service, test.region)
+		res, err := a.Decode(newMockReadCloser(fakeData))
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.want, res, test.name)
+	}
+}
@@ -235,3 +235,51 @@ func BenchmarkCachedIPLister(b *testing.B) {
 
 	b.ReportMetric(ec, "Errors")
 }
+
+func TestCachedIPListerWatch(t *testing.T) {
+	t.Parallel()
+
+	mr := &mockReader{ret: "1.2.3.4/32"}
+	md := &mockDecoder{ret: []string{"1.2.3.4/32", "2.3.4.5/32"}}
+	ipl := NewCachedIPLister(New(mr, md))
+
+	ipl.lock.Lock()
+	ipl.syncInterval = 10 * time.Millisecond
+	ipl.lock.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notified := make(chan struct{}, 10)
+	ipl.Watch(ctx, func() { notified <- struct{}{} })
+
+	// reordered lists are not a change
+	ipl.lock.Lock()
+	md.ret = []string{"2.3.4.5/32", "1.2.3.4/32"}
+	ipl.lastSync = ipl.lastSync.Add(-time.Minute)
+	ipl.lock.Unlock()
+
+	_, err := ipl.GetIPs()
+	assert.NoError(t, err)
+	assert.Empty(t, notified)
+
+	// the watch refreshes stale lists without GetIPs being called
+	ipl.lock.Lock()
+	md.ret = []string{"3.4.5.6/32"}
+	ipl.lock.Unlock()
+
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change notification")
+	}
+
+	ips, err := ipl.GetIPs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3.4.5.6/32"}, ips)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		ipl.lock.RLock()
+		defer ipl.lock.RUnlock()
+		return len(ipl.watchers) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -2,10 +2,21 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
+
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
 )
 
+// ReasonThrottled is the reason code of errors for requests the ip provider rate limited.
+const ReasonThrottled string = "IPProviderThrottled"
+
+// DefaultThrottleRetryAfter is the retry hint of throttled requests without a Retry-After header.
+const DefaultThrottleRetryAfter = 5 * time.Minute
+
 type HTTP struct {
 	url      string
 	username string
@@ -41,5 +52,50 @@ func (h *HTTP) Data(ctx context.Context) (io.ReadCloser, error) {
 		return nil, err
 	}
 
+	if throttled(resp) {
+		resp.Body.Close()
+		return nil, perrs.NewRetryableError(
+			fmt.Errorf("%s throttled the request with status %d", h.url, resp.StatusCode),
+			perrs.WithReason(ReasonThrottled),
+			perrs.WithRetryAfter(retryAfter(resp, time.Now())),
+		)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s responded with status %d", h.url, resp.StatusCode)
+	}
+
 	return resp.Body, nil
 }
+
+// throttled reports rate limited responses, GitHub answers exhausted rate limits with a 403.
+func throttled(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// retryAfter reads the retry hint of a throttled response from the Retry-After header in seconds or
+// as a date, or the GitHub X-RateLimit-Reset epoch.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(value); err == nil && at.After(now) {
+			return at.Sub(now)
+		}
+	}
+
+	if value := resp.Header.Get("X-RateLimit-Reset"); value != "" {
+		if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if at := time.Unix(epoch, 0); at.After(now) {
+				return at.Sub(now)
+			}
+		}
+	}
+
+	return DefaultThrottleRetryAfter
+}
@@ -5,7 +5,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
+
+	perrs "github.com/kanopy-platform/argoslower/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -51,3 +55,66 @@ func TestData(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, fakeResponse, result)
 }
+
+func TestDataStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		status     int
+		headers    map[string]string
+		wantErr    bool
+		throttled  bool
+		retryAfter time.Duration
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+		{name: "too many requests", status: http.StatusTooManyRequests, wantErr: true, throttled: true, retryAfter: DefaultThrottleRetryAfter},
+		{name: "retry after seconds", status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "30"}, wantErr: true, throttled: true, retryAfter: 30 * time.Second},
+		{name: "github rate limit", status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0"}, wantErr: true, throttled: true, retryAfter: DefaultThrottleRetryAfter},
+		{name: "forbidden", status: http.StatusForbidden, wantErr: true},
+	}
+
+	for _, test := range tests {
+		testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			for k, v := range test.headers {
+				res.Header().Set(k, v)
+			}
+			res.WriteHeader(test.status)
+		}))
+
+		readCloser, err := New(testServer.URL).Data(context.Background())
+		testServer.Close()
+
+		assert.Equal(t, test.wantErr, err != nil, test.name)
+		if err == nil {
+			readCloser.Close()
+			continue
+		}
+
+		if test.throttled {
+			assert.Equal(t, ReasonThrottled, perrs.ReasonOf(err), test.name)
+			assert.Equal(t, test.retryAfter, perrs.RetryAfterOf(err), test.name)
+		} else {
+			assert.Empty(t, perrs.ReasonOf(err), test.name)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", now.Add(time.Minute).Format(http.TimeFormat))
+	assert.Equal(t, time.Minute, retryAfter(resp, now))
+
+	resp = &http.Response{Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10))
+	assert.Equal(t, 10*time.Minute, retryAfter(resp, now))
+
+	resp = &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "soon")
+	assert.Equal(t, DefaultThrottleRetryAfter, retryAfter(resp, now))
+}
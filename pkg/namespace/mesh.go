@@ -0,0 +1,88 @@
+package namespace
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DataplaneMode is the istio dataplane mode workloads of a namespace run in.
+type DataplaneMode string
+
+const (
+	DataplaneNone    DataplaneMode = ""
+	DataplaneSidecar DataplaneMode = "sidecar"
+	DataplaneAmbient DataplaneMode = "ambient"
+)
+
+const (
+	DefaultRevisionLabel  string = "istio.io/rev"
+	DefaultInjectionLabel string = "istio-injection=enabled"
+	DefaultAmbientLabel   string = "istio.io/dataplane-mode=ambient"
+)
+
+// MeshInfo describes how a namespace is enrolled in the mesh. Revision is only set for sidecar
+// namespaces selecting an istio revision or revision tag.
+type MeshInfo struct {
+	Mode     DataplaneMode
+	Revision string
+}
+
+// MeshDetector detects the mesh enrollment of a namespace. DataplaneNone is returned when the
+// detector does not apply to the namespace.
+type MeshDetector interface {
+	Detect(ns *corev1.Namespace) MeshInfo
+}
+
+// RevisionDetector detects sidecar namespaces selecting a revision or revision tag with the label
+// key. The label value is returned as the revision.
+type RevisionDetector struct {
+	Label string
+}
+
+func (d RevisionDetector) Detect(ns *corev1.Namespace) MeshInfo {
+	if val, ok := ns.Labels[d.Label]; ok && val != "" {
+		return MeshInfo{Mode: DataplaneSidecar, Revision: val}
+	}
+	return MeshInfo{}
+}
+
+// LabelDetector detects namespaces of the given dataplane mode by a label key and value.
+type LabelDetector struct {
+	Label string
+	Value string
+	Mode  DataplaneMode
+}
+
+func (d LabelDetector) Detect(ns *corev1.Namespace) MeshInfo {
+	if val, ok := ns.Labels[d.Label]; ok && val == d.Value {
+		return MeshInfo{Mode: d.Mode}
+	}
+	return MeshInfo{}
+}
+
+// NewMeshDetectors builds the mesh detectors for the configured labels in istio precedence order,
+// sidecar injection before revisions before ambient. The injection and ambient labels are key=value
+// pairs. Empty labels disable the respective detector.
+func NewMeshDetectors(injectionLabel, revisionLabel, ambientLabel string) []MeshDetector {
+	out := []MeshDetector{}
+
+	if key, value, ok := splitLabel(injectionLabel); ok {
+		out = append(out, LabelDetector{Label: key, Value: value, Mode: DataplaneSidecar})
+	}
+
+	if revisionLabel != "" {
+		out = append(out, RevisionDetector{Label: revisionLabel})
+	}
+
+	if key, value, ok := splitLabel(ambientLabel); ok {
+		out = append(out, LabelDetector{Label: key, Value: value, Mode: DataplaneAmbient})
+	}
+
+	return out
+}
+
+func splitLabel(in string) (string, string, bool) {
+	key, value, ok := strings.Cut(in, "=")
+	return key, value, ok && key != ""
+}
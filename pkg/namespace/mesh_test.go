@@ -0,0 +1,112 @@
+package namespace
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMeshMode(t *testing.T) {
+	t.Parallel()
+
+	nsWithLabels := func(labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Labels: labels}}
+	}
+
+	lister := &MockNamespaceLister{
+		namespaces: map[string]*corev1.Namespace{
+			"revision":  nsWithLabels(map[string]string{"istio.io/rev": "stable"}),
+			"injection": nsWithLabels(map[string]string{"istio-injection": "enabled"}),
+			"disabled":  nsWithLabels(map[string]string{"istio-injection": "disabled"}),
+			"ambient":   nsWithLabels(map[string]string{"istio.io/dataplane-mode": "ambient"}),
+			"both": nsWithLabels(map[string]string{
+				"istio-injection":         "enabled",
+				"istio.io/rev":            "stable",
+				"istio.io/dataplane-mode": "ambient",
+			}),
+			"rev-ambient": nsWithLabels(map[string]string{
+				"istio.io/rev":            "stable",
+				"istio.io/dataplane-mode": "ambient",
+			}),
+			"empty-rev": nsWithLabels(map[string]string{"istio.io/rev": ""}),
+			"none":      nsWithLabels(nil),
+		},
+	}
+
+	detectors := NewMeshDetectors(DefaultInjectionLabel, DefaultRevisionLabel, DefaultAmbientLabel)
+
+	tests := []struct {
+		namespace string
+		detectors []MeshDetector
+		expected  MeshInfo
+		err       error
+	}{
+		{namespace: "revision", expected: MeshInfo{Mode: DataplaneSidecar, Revision: "stable"}},
+		{namespace: "injection", expected: MeshInfo{Mode: DataplaneSidecar}},
+		{namespace: "disabled"},
+		{namespace: "ambient", expected: MeshInfo{Mode: DataplaneAmbient}},
+		{namespace: "both", expected: MeshInfo{Mode: DataplaneSidecar}},
+		{namespace: "rev-ambient", expected: MeshInfo{Mode: DataplaneSidecar, Revision: "stable"}},
+		{namespace: "empty-rev"},
+		{namespace: "none"},
+		{namespace: ""},
+		{namespace: "ambient", detectors: NewMeshDetectors("", DefaultRevisionLabel, "")},
+		{namespace: "missing", err: fmt.Errorf("not found")},
+	}
+
+	for _, test := range tests {
+		lister.err = test.err
+		n := NewNamespaceInfo(lister, "", "")
+		if test.detectors == nil {
+			n.SetMeshDetectors(detectors...)
+		} else {
+			n.SetMeshDetectors(test.detectors...)
+		}
+
+		info, err := n.MeshMode(test.namespace)
+		assert.Equal(t, test.err, err, test.namespace)
+		assert.Equal(t, test.expected, info, test.namespace)
+
+		onMesh, err := n.OnMesh(test.namespace)
+		assert.Equal(t, test.err, err, test.namespace)
+		assert.Equal(t, test.expected.Mode != DataplaneNone, onMesh, test.namespace)
+	}
+	lister.err = nil
+}
+
+func TestOnMeshDefaultDetectors(t *testing.T) {
+	t.Parallel()
+
+	lister := &MockNamespaceLister{
+		namespaces: map[string]*corev1.Namespace{
+			"revision":  {ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"istio.io/rev": "stable"}}},
+			"injection": {ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"istio-injection": "enabled"}}},
+		},
+	}
+
+	n := NewNamespaceInfo(lister, "", "")
+
+	onMesh, err := n.OnMesh("revision")
+	assert.NoError(t, err)
+	assert.True(t, onMesh)
+
+	onMesh, err = n.OnMesh("injection")
+	assert.NoError(t, err)
+	assert.False(t, onMesh)
+}
+
+func TestNewMeshDetectors(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []MeshDetector{
+		LabelDetector{Label: "istio-injection", Value: "enabled", Mode: DataplaneSidecar},
+		RevisionDetector{Label: "istio.io/rev"},
+		LabelDetector{Label: "istio.io/dataplane-mode", Value: "ambient", Mode: DataplaneAmbient},
+	}, NewMeshDetectors(DefaultInjectionLabel, DefaultRevisionLabel, DefaultAmbientLabel))
+
+	assert.Equal(t, []MeshDetector{}, NewMeshDetectors("", "", ""))
+	assert.Equal(t, []MeshDetector{}, NewMeshDetectors("invalid", "", "=ambient"))
+}
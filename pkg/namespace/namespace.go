@@ -6,12 +6,18 @@ import (
 
 	sensor "github.com/argoproj/argo-events/pkg/apis/events/v1alpha1"
 	corev1Listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/kanopy-platform/argoslower/pkg/stringutils"
 )
 
 type NamespaceInfo struct {
 	lister                    corev1Listers.NamespaceLister
 	rateLimitUnitAnnotation   string
 	requestsPerUnitAnnotation string
+	allowedSourcesAnnotation  string
+	strictExemptAnnotation    string
+	approvalAnnotation        string
+	meshDetectors             []MeshDetector
 }
 
 func NewNamespaceInfo(lister corev1Listers.NamespaceLister, rateLimitUnitAnnotation, requestsPerUnitAnnotation string) *NamespaceInfo {
@@ -19,9 +25,34 @@ func NewNamespaceInfo(lister corev1Listers.NamespaceLister, rateLimitUnitAnnotat
 		lister:                    lister,
 		rateLimitUnitAnnotation:   rateLimitUnitAnnotation,
 		requestsPerUnitAnnotation: requestsPerUnitAnnotation,
+		meshDetectors:             []MeshDetector{RevisionDetector{Label: DefaultRevisionLabel}},
 	}
 }
 
+// SetStrictExemptAnnotation configures the namespace annotation key exempting a namespace from
+// strict webhook ingress enforcement when set to "true".
+func (n *NamespaceInfo) SetStrictExemptAnnotation(key string) {
+	n.strictExemptAnnotation = key
+}
+
+// SetApprovalAnnotation configures the namespace annotation key requiring approval of webhook
+// endpoints exposed by EventSources in the namespace when set to "true".
+func (n *NamespaceInfo) SetApprovalAnnotation(key string) {
+	n.approvalAnnotation = key
+}
+
+// SetMeshDetectors configures the detectors used to identify the mesh enrollment of a namespace.
+// The first detector matching a namespace wins.
+func (n *NamespaceInfo) SetMeshDetectors(detectors ...MeshDetector) {
+	n.meshDetectors = detectors
+}
+
+// SetAllowedSourcesAnnotation configures the namespace annotation key listing the known webhook
+// sources EventSources in the namespace may use.
+func (n *NamespaceInfo) SetAllowedSourcesAnnotation(key string) {
+	n.allowedSourcesAnnotation = key
+}
+
 // Retrieves the namespace RateLimit values if exists, nil otherwise.
 func (n *NamespaceInfo) RateLimit(namespace string) (*sensor.RateLimit, error) {
 	if namespace == "" {
@@ -62,7 +93,65 @@ func (n *NamespaceInfo) RateLimit(namespace string) (*sensor.RateLimit, error) {
 }
 
 func (n *NamespaceInfo) OnMesh(namespace string) (bool, error) {
+	info, err := n.MeshMode(namespace)
+	if err != nil {
+		return false, err
+	}
+
+	return info.Mode != DataplaneNone, nil
+}
+
+// MeshMode detects the dataplane mode and revision of a namespace with the configured detectors.
+func (n *NamespaceInfo) MeshMode(namespace string) (MeshInfo, error) {
 	if namespace == "" {
+		return MeshInfo{}, nil
+	}
+
+	ns, err := n.lister.Get(namespace)
+	if err != nil {
+		return MeshInfo{}, err
+	}
+
+	for _, detector := range n.meshDetectors {
+		if info := detector.Detect(ns); info.Mode != DataplaneNone {
+			return info, nil
+		}
+	}
+
+	return MeshInfo{}, nil
+}
+
+// AllowedSources retrieves the comma separated list of known webhook sources a namespace may use
+// from the allowed sources annotation. The returned bool is false when the annotation is not set.
+func (n *NamespaceInfo) AllowedSources(namespace string) ([]string, bool, error) {
+	if namespace == "" {
+		return nil, false, fmt.Errorf("invalid namespace; %q", namespace)
+	}
+
+	if n.allowedSourcesAnnotation == "" {
+		return nil, false, nil
+	}
+
+	ns, err := n.lister.Get(namespace)
+	if err != nil {
+		return nil, false, err
+	}
+
+	val, ok := ns.Annotations[n.allowedSourcesAnnotation]
+	if !ok {
+		return nil, false, nil
+	}
+
+	return stringutils.SplitTrim(val, ","), true, nil
+}
+
+// StrictExempt reports whether a namespace is exempt from strict webhook ingress enforcement.
+func (n *NamespaceInfo) StrictExempt(namespace string) (bool, error) {
+	if namespace == "" {
+		return false, fmt.Errorf("invalid namespace; %q", namespace)
+	}
+
+	if n.strictExemptAnnotation == "" {
 		return false, nil
 	}
 
@@ -71,8 +160,33 @@ func (n *NamespaceInfo) OnMesh(namespace string) (bool, error) {
 		return false, err
 	}
 
-	if val, ok := ns.Labels["istio.io/rev"]; !ok || val == "" {
+	val, ok := ns.Annotations[n.strictExemptAnnotation]
+	if !ok {
 		return false, nil
 	}
-	return true, nil
+
+	return strconv.ParseBool(val)
+}
+
+// ApprovalRequired reports whether webhook endpoints exposed in a namespace require approval.
+func (n *NamespaceInfo) ApprovalRequired(namespace string) (bool, error) {
+	if namespace == "" {
+		return false, fmt.Errorf("invalid namespace; %q", namespace)
+	}
+
+	if n.approvalAnnotation == "" {
+		return false, nil
+	}
+
+	ns, err := n.lister.Get(namespace)
+	if err != nil {
+		return false, err
+	}
+
+	val, ok := ns.Annotations[n.approvalAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	return strconv.ParseBool(val)
 }
@@ -170,3 +170,173 @@ func TestRateLimit(t *testing.T) {
 		assert.Equal(t, test.wantError, err != nil)
 	}
 }
+
+func TestAllowedSources(t *testing.T) {
+	t.Parallel()
+
+	annotation := "allowed-sources"
+
+	lister := &MockNamespaceLister{
+		namespaces: map[string]*corev1.Namespace{
+			"annotated": &corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						annotation: "github, jira,,",
+					},
+				},
+			},
+			"empty": &corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						annotation: "",
+					},
+				},
+			},
+			"default": &corev1.Namespace{},
+		},
+	}
+
+	tests := []struct {
+		testMsg    string
+		namespace  string
+		annotation string
+		wantResult []string
+		wantSet    bool
+		wantError  bool
+	}{
+		{
+			testMsg:    "annotation lists sources",
+			namespace:  "annotated",
+			annotation: annotation,
+			wantResult: []string{"github", "jira"},
+			wantSet:    true,
+		},
+		{
+			testMsg:    "empty annotation allows nothing",
+			namespace:  "empty",
+			annotation: annotation,
+			wantResult: []string{},
+			wantSet:    true,
+		},
+		{
+			testMsg:    "annotation missing",
+			namespace:  "default",
+			annotation: annotation,
+		},
+		{
+			testMsg:   "annotation key not configured",
+			namespace: "annotated",
+		},
+		{
+			testMsg:    "invalid namespace",
+			annotation: annotation,
+			wantError:  true,
+		},
+	}
+
+	for _, test := range tests {
+		n := NewNamespaceInfo(lister, "", "")
+		n.SetAllowedSourcesAnnotation(test.annotation)
+
+		result, set, err := n.AllowedSources(test.namespace)
+		assert.Equal(t, test.wantResult, result, test.testMsg)
+		assert.Equal(t, test.wantSet, set, test.testMsg)
+		assert.Equal(t, test.wantError, err != nil, test.testMsg)
+	}
+}
+
+func TestStrictExempt(t *testing.T) {
+	t.Parallel()
+
+	annotation := "unmanaged-webhooks"
+
+	lister := &MockNamespaceLister{
+		namespaces: map[string]*corev1.Namespace{
+			"exempt": &corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{annotation: "true"},
+				},
+			},
+			"not-exempt": &corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{annotation: "false"},
+				},
+			},
+			"invalid": &corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{annotation: "yes please"},
+				},
+			},
+			"default": &corev1.Namespace{},
+		},
+	}
+
+	tests := []struct {
+		testMsg    string
+		namespace  string
+		annotation string
+		wantResult bool
+		wantError  bool
+	}{
+		{testMsg: "exempt", namespace: "exempt", annotation: annotation, wantResult: true},
+		{testMsg: "not exempt", namespace: "not-exempt", annotation: annotation},
+		{testMsg: "annotation missing", namespace: "default", annotation: annotation},
+		{testMsg: "annotation key not configured", namespace: "exempt"},
+		{testMsg: "invalid annotation value", namespace: "invalid", annotation: annotation, wantError: true},
+		{testMsg: "invalid namespace", annotation: annotation, wantError: true},
+	}
+
+	for _, test := range tests {
+		n := NewNamespaceInfo(lister, "", "")
+		n.SetStrictExemptAnnotation(test.annotation)
+
+		result, err := n.StrictExempt(test.namespace)
+		assert.Equal(t, test.wantResult, result, test.testMsg)
+		assert.Equal(t, test.wantError, err != nil, test.testMsg)
+	}
+}
+
+func TestApprovalRequired(t *testing.T) {
+	t.Parallel()
+
+	annotation := "require-ingress-approval"
+
+	lister := &MockNamespaceLister{
+		namespaces: map[string]*corev1.Namespace{
+			"regulated": &corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{annotation: "true"},
+				},
+			},
+			"invalid": &corev1.Namespace{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{annotation: "sometimes"},
+				},
+			},
+			"default": &corev1.Namespace{},
+		},
+	}
+
+	tests := []struct {
+		testMsg    string
+		namespace  string
+		annotation string
+		wantResult bool
+		wantError  bool
+	}{
+		{testMsg: "required", namespace: "regulated", annotation: annotation, wantResult: true},
+		{testMsg: "annotation missing", namespace: "default", annotation: annotation},
+		{testMsg: "annotation key not configured", namespace: "regulated"},
+		{testMsg: "invalid annotation value", namespace: "invalid", annotation: annotation, wantError: true},
+		{testMsg: "invalid namespace", annotation: annotation, wantError: true},
+	}
+
+	for _, test := range tests {
+		n := NewNamespaceInfo(lister, "", "")
+		n.SetApprovalAnnotation(test.annotation)
+
+		result, err := n.ApprovalRequired(test.namespace)
+		assert.Equal(t, test.wantResult, result, test.testMsg)
+		assert.Equal(t, test.wantError, err != nil, test.testMsg)
+	}
+}
@@ -0,0 +1,97 @@
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1Listers "k8s.io/client-go/listers/core/v1"
+)
+
+// Scope restricts an argoslower instance to a set of namespaces and to namespaces matching a label
+// selector. An empty scope contains every namespace.
+type Scope struct {
+	namespaces []string
+	selector   labels.Selector
+	lister     corev1Listers.NamespaceLister
+	getter     NamespaceGetter
+}
+
+// NamespaceGetter looks up namespaces through the API server.
+type NamespaceGetter interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Namespace, error)
+}
+
+// NewScope returns a scope of the listed namespaces matching the selector. The lister is only
+// required for non empty selectors.
+func NewScope(namespaces []string, selector labels.Selector, lister corev1Listers.NamespaceLister) (*Scope, error) {
+	if selector == nil {
+		selector = labels.Everything()
+	}
+
+	if !selector.Empty() && lister == nil {
+		return nil, fmt.Errorf("a namespace lister is required for the namespace selector %s", selector.String())
+	}
+
+	out := slices.Clone(namespaces)
+	slices.Sort(out)
+
+	return &Scope{
+		namespaces: slices.Compact(out),
+		selector:   selector,
+		lister:     lister,
+	}, nil
+}
+
+// Namespaces returns the namespaces of the scope or nil when the scope is not limited to a list of
+// namespaces.
+func (s *Scope) Namespaces() []string {
+	if s == nil || len(s.namespaces) == 0 {
+		return nil
+	}
+	return slices.Clone(s.namespaces)
+}
+
+// SetNamespaceGetter configures the lookup of namespaces missing from the lister, i.e. namespaces
+// created together with their objects before the informer cache caught up.
+func (s *Scope) SetNamespaceGetter(g NamespaceGetter) {
+	s.getter = g
+}
+
+// InScope reports whether a namespace belongs to the scope. Namespaces that do not exist are out of
+// scope of a selector.
+func (s *Scope) InScope(namespace string) (bool, error) {
+	if s == nil {
+		return true, nil
+	}
+
+	if len(s.namespaces) > 0 {
+		if _, found := slices.BinarySearch(s.namespaces, namespace); !found {
+			return false, nil
+		}
+	}
+
+	if s.selector.Empty() {
+		return true, nil
+	}
+
+	ns, err := s.lister.Get(namespace)
+	if k8serrors.IsNotFound(err) && s.getter != nil {
+		ns, err = s.getter.Get(context.TODO(), namespace, metav1.GetOptions{})
+	}
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if ns == nil {
+		return false, nil
+	}
+
+	return s.selector.Matches(labels.Set(ns.Labels)), nil
+}
@@ -0,0 +1,128 @@
+package namespace
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1Listers "k8s.io/client-go/listers/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestScope(t *testing.T) {
+	t.Parallel()
+
+	lister := &MockNamespaceLister{
+		namespaces: map[string]*corev1.Namespace{
+			"tenant-a": &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant": "a"}}},
+			"tenant-b": &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "tenant-b", Labels: map[string]string{"tenant": "b"}}},
+			"other":    &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "other"}},
+		},
+	}
+
+	tenantA, err := labels.Parse("tenant=a")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		selector   labels.Selector
+		lister     *MockNamespaceLister
+		want       map[string]bool
+		err        bool
+	}{
+		{
+			name:   "everything",
+			lister: lister,
+			want:   map[string]bool{"tenant-a": true, "tenant-b": true, "other": true, "missing": true},
+		},
+		{
+			name:       "namespaces",
+			namespaces: []string{"tenant-b", "other", "tenant-b"},
+			lister:     lister,
+			want:       map[string]bool{"tenant-a": false, "tenant-b": true, "other": true, "missing": false},
+		},
+		{
+			name:     "selector",
+			selector: tenantA,
+			lister:   lister,
+			want:     map[string]bool{"tenant-a": true, "tenant-b": false, "other": false, "missing": false},
+		},
+		{
+			name:       "namespaces and selector",
+			namespaces: []string{"tenant-a", "tenant-b"},
+			selector:   labels.SelectorFromSet(labels.Set{"tenant": "b"}),
+			lister:     lister,
+			want:       map[string]bool{"tenant-a": false, "tenant-b": true, "other": false},
+		},
+		{
+			name:     "lister error",
+			selector: tenantA,
+			lister:   &MockNamespaceLister{err: fmt.Errorf("failed")},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		scope, err := NewScope(test.namespaces, test.selector, test.lister)
+		require.NoError(t, err, test.name)
+
+		if test.err {
+			_, err := scope.InScope("tenant-a")
+			assert.Error(t, err, test.name)
+			continue
+		}
+
+		for namespace, want := range test.want {
+			got, err := scope.InScope(namespace)
+			assert.NoError(t, err, test.name)
+			assert.Equal(t, want, got, fmt.Sprintf("%s %s", test.name, namespace))
+		}
+	}
+
+	_, err = NewScope(nil, tenantA, nil)
+	assert.Error(t, err)
+
+	var empty *Scope
+	inScope, err := empty.InScope("other")
+	assert.NoError(t, err)
+	assert.True(t, inScope)
+	assert.Nil(t, empty.Namespaces())
+}
+
+func TestScopeNamespaceGetter(t *testing.T) {
+	t.Parallel()
+
+	tenantA, err := labels.Parse("tenant=a")
+	require.NoError(t, err)
+
+	lister := corev1Listers.NewNamespaceLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))
+	scope, err := NewScope(nil, tenantA, lister)
+	require.NoError(t, err)
+
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant": "a"}}},
+		&corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "other"}},
+	)
+	scope.SetNamespaceGetter(client.CoreV1().Namespaces())
+
+	// namespaces missing from the cache are looked up through the API server
+	for namespace, want := range map[string]bool{"tenant-a": true, "other": false, "missing": false} {
+		got, err := scope.InScope(namespace)
+		assert.NoError(t, err, namespace)
+		assert.Equal(t, want, got, namespace)
+	}
+
+	client.PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("failed")
+	})
+	_, err = scope.InScope("tenant-a")
+	assert.Error(t, err)
+}
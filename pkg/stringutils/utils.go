@@ -17,3 +17,18 @@ func StringToMap(in, delim, split string) map[string]string {
 
 	return out
 }
+
+// SplitTrim splits a delim delimited string into its whitespace trimmed values,
+// omitting empty values. i.e. " a, b,," yields [a b]
+func SplitTrim(in, delim string) []string {
+	out := []string{}
+	for _, v := range strings.Split(in, delim) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		out = append(out, v)
+	}
+
+	return out
+}
@@ -65,3 +65,23 @@ func TestStringToMap(t *testing.T) {
 
 	}
 }
+
+func TestSplitTrim(t *testing.T) {
+
+	tests := map[string]struct {
+		in       string
+		expected []string
+	}{
+		"empty":   {expected: []string{}},
+		"good":    {in: "a,b,c", expected: []string{"a", "b", "c"}},
+		"trimmed": {in: " a , b", expected: []string{"a", "b"}},
+		"omitempty": {
+			in:       "a,, ,b,",
+			expected: []string{"a", "b"},
+		},
+	}
+
+	for name, test := range tests {
+		assert.Equal(t, test.expected, SplitTrim(test.in, ","), name)
+	}
+}